@@ -0,0 +1,135 @@
+package gonkaopenai
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil/bech32"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ErrInvalidAddress is returned for strings that are not valid Gonka bech32 addresses.
+var ErrInvalidAddress = errors.New("invalid address")
+
+// ErrKeyAddressMismatch is matched by *KeyAddressMismatchError.
+var ErrKeyAddressMismatch = errors.New("requester address does not match private key")
+
+// KeyAddressMismatchError is returned when a configured requester address is not
+// the address of the signing key and the mismatch was not explicitly allowed.
+type KeyAddressMismatchError struct {
+	Configured string
+	Derived    string
+}
+
+func (e *KeyAddressMismatchError) Error() string {
+	return fmt.Sprintf("%v: configured %s, key belongs to %s", ErrKeyAddressMismatch, e.Configured, e.Derived)
+}
+
+func (e *KeyAddressMismatchError) Unwrap() error { return ErrKeyAddressMismatch }
+
+// addressLength is the payload length of account and validator operator addresses.
+const addressLength = 20
+
+// valoperSuffix turns an account prefix into the validator operator prefix.
+const valoperSuffix = "valoper"
+
+// DecodeAddress decodes a bech32 address, verifying its checksum and that the
+// payload is a 20-byte account hash. It returns the prefix and the payload.
+func DecodeAddress(address string) (string, []byte, error) {
+	prefix, data, err := bech32.Decode(address)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w %q: %v", ErrInvalidAddress, address, err)
+	}
+	payload, err := bech32.ConvertBits(data, 5, 8, false)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w %q: %v", ErrInvalidAddress, address, err)
+	}
+	if len(payload) != addressLength {
+		return "", nil, fmt.Errorf("%w %q: expected %d-byte payload, got %d", ErrInvalidAddress, address, addressLength, len(payload))
+	}
+	return prefix, payload, nil
+}
+
+// ValidateAddress checks that address is a valid bech32 address with the given prefix.
+func ValidateAddress(address, prefix string) error {
+	got, _, err := DecodeAddress(address)
+	if err != nil {
+		return err
+	}
+	if got != prefix {
+		return fmt.Errorf("%w %q: expected prefix %q, got %q", ErrInvalidAddress, address, prefix, got)
+	}
+	return nil
+}
+
+// ValidateAddress checks that address is a valid account address on the network.
+func (n Network) ValidateAddress(address string) error {
+	return ValidateAddress(address, n.AddressPrefix())
+}
+
+// ToValoperAddress converts an account address (gonka1...) to the validator
+// operator address (gonkavaloper1...) of the same key.
+func ToValoperAddress(address string) (string, error) {
+	prefix, payload, err := DecodeAddress(address)
+	if err != nil {
+		return "", err
+	}
+	if strings.HasSuffix(prefix, valoperSuffix) {
+		return "", fmt.Errorf("%w %q: already a validator operator address", ErrInvalidAddress, address)
+	}
+	return encodeAddress(prefix+valoperSuffix, payload)
+}
+
+// ToAccountAddress converts a validator operator address (gonkavaloper1...) to
+// the account address (gonka1...) of the same key.
+func ToAccountAddress(valoperAddress string) (string, error) {
+	prefix, payload, err := DecodeAddress(valoperAddress)
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasSuffix(prefix, valoperSuffix) || prefix == valoperSuffix {
+		return "", fmt.Errorf("%w %q: not a validator operator address", ErrInvalidAddress, valoperAddress)
+	}
+	return encodeAddress(strings.TrimSuffix(prefix, valoperSuffix), payload)
+}
+
+// AddressFromPubKey derives the bech32 address with the given prefix from a
+// 33-byte compressed secp256k1 public key.
+func AddressFromPubKey(pubKey []byte, prefix string) (string, error) {
+	if len(pubKey) != 33 {
+		return "", fmt.Errorf("expected 33-byte compressed public key, got %d bytes", len(pubKey))
+	}
+	if _, err := crypto.DecompressPubkey(pubKey); err != nil {
+		return "", fmt.Errorf("invalid public key: %w", err)
+	}
+	return pubKeyToAddress(pubKey, prefix)
+}
+
+func encodeAddress(prefix string, payload []byte) (string, error) {
+	five, err := bech32.ConvertBits(payload, 8, 5, true)
+	if err != nil {
+		return "", err
+	}
+	return bech32.Encode(prefix, five)
+}
+
+// resolveRequesterAddress returns the address to send as X-Requester-Address.
+// An empty configured address is derived from the key; otherwise it must be a
+// valid address on the network and, unless allowMismatch is set, the key's own.
+func resolveRequesterAddress(network Network, privateKeyHex, configured string, allowMismatch bool) (string, error) {
+	derived, err := network.Address(privateKeyHex)
+	if err != nil {
+		return "", err
+	}
+	if configured == "" {
+		return derived, nil
+	}
+	if err := network.ValidateAddress(configured); err != nil {
+		return "", fmt.Errorf("invalid requester address: %w", err)
+	}
+	if configured != derived && !allowMismatch {
+		return "", &KeyAddressMismatchError{Configured: configured, Derived: derived}
+	}
+	return configured, nil
+}
@@ -0,0 +1,101 @@
+package gonkaopenai
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeAddress(t *testing.T) {
+	prefix, payload, err := DecodeAddress(testTransferAddress)
+	require.NoError(t, err)
+	assert.Equal(t, "gonka", prefix)
+	assert.Len(t, payload, 20)
+
+	// Broken checksum
+	_, _, err = DecodeAddress(testTransferAddress[:len(testTransferAddress)-1] + "q")
+	assert.ErrorIs(t, err, ErrInvalidAddress)
+
+	// Wrong payload length
+	short, err := encodeAddress("gonka", []byte{1, 2, 3})
+	require.NoError(t, err)
+	_, _, err = DecodeAddress(short)
+	assert.ErrorIs(t, err, ErrInvalidAddress)
+
+	assert.NoError(t, ValidateAddress(testTransferAddress, "gonka"))
+	assert.ErrorIs(t, ValidateAddress(testTransferAddress, "cosmos"), ErrInvalidAddress)
+}
+
+func TestValoperConversion(t *testing.T) {
+	valoper, err := ToValoperAddress(testTransferAddress)
+	require.NoError(t, err)
+	assert.Contains(t, valoper, "gonkavaloper1")
+
+	account, err := ToAccountAddress(valoper)
+	require.NoError(t, err)
+	assert.Equal(t, testTransferAddress, account)
+
+	_, err = ToAccountAddress(testTransferAddress)
+	assert.ErrorIs(t, err, ErrInvalidAddress)
+	_, err = ToValoperAddress(valoper)
+	assert.ErrorIs(t, err, ErrInvalidAddress)
+}
+
+func TestAddressFromPubKey(t *testing.T) {
+	keyBytes, _ := hex.DecodeString(testPrivateKey)
+	priv, err := crypto.ToECDSA(keyBytes)
+	require.NoError(t, err)
+
+	addr, err := AddressFromPubKey(crypto.CompressPubkey(&priv.PublicKey), "gonka")
+	require.NoError(t, err)
+	assert.Equal(t, mustAddress(t, testPrivateKey), addr)
+
+	_, err = AddressFromPubKey([]byte{1, 2, 3}, "gonka")
+	assert.Error(t, err)
+}
+
+func TestParseEndpoints(t *testing.T) {
+	eps, err := ParseEndpoints("http://a.test;" + testTransferAddress + ", ,http://b.test ; " + testTransferAddress)
+	require.NoError(t, err)
+	assert.Len(t, eps, 2)
+
+	_, err = ParseEndpoints("http://a.test;gonka1notanaddress")
+	assert.ErrorIs(t, err, ErrInvalidAddress)
+
+	_, err = ParseEndpoints("http://a.test")
+	assert.Error(t, err)
+
+	t.Setenv(EnvEndpoints, "http://a.test;gonka1notanaddress,http://b.test;"+testTransferAddress)
+	assert.Equal(t, []Endpoint{{URL: "http://b.test", Address: testTransferAddress}}, GetEndpointsFromEnv())
+
+	_, err = NewGonkaOpenAI(Options{GonkaPrivateKey: testPrivateKey})
+	assert.ErrorIs(t, err, ErrInvalidAddress)
+}
+
+func TestRequesterAddressMustMatchKey(t *testing.T) {
+	endpoints := []Endpoint{{URL: "http://participant.test/v1", Address: testTransferAddress}}
+
+	_, err := NewGonkaOpenAI(Options{GonkaPrivateKey: testPrivateKey, GonkaAddress: testTransferAddress, Endpoints: endpoints})
+	assert.ErrorIs(t, err, ErrKeyAddressMismatch)
+	var mismatch *KeyAddressMismatchError
+	require.ErrorAs(t, err, &mismatch)
+	assert.Equal(t, mustAddress(t, testPrivateKey), mismatch.Derived)
+
+	_, err = GonkaHTTPClient(HTTPClientOptions{PrivateKey: testPrivateKey, Address: testTransferAddress, Endpoints: endpoints})
+	assert.ErrorIs(t, err, ErrKeyAddressMismatch)
+
+	g, err := NewGonkaOpenAI(Options{
+		GonkaPrivateKey:      testPrivateKey,
+		GonkaAddress:         testTransferAddress,
+		Endpoints:            endpoints,
+		AllowAddressMismatch: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, testTransferAddress, g.GonkaAddress())
+
+	_, err = NewGonkaOpenAI(Options{GonkaPrivateKey: testPrivateKey, GonkaAddress: "gonka1abc", Endpoints: endpoints, AllowAddressMismatch: true})
+	assert.ErrorIs(t, err, ErrInvalidAddress)
+}
@@ -0,0 +1,277 @@
+package gonkaopenai
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	cmtbytes "github.com/cometbft/cometbft/libs/bytes"
+	cryptotypes "github.com/cometbft/cometbft/proto/tendermint/crypto"
+	"github.com/gonka-ai/gonka-openai/go/chainclient"
+	"go.opentelemetry.io/otel/attribute"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// ErrProofVerification is returned when a proof was obtained but does not verify.
+// Unlike a missing proof, this indicates that the node returned inconsistent data.
+var ErrProofVerification = errors.New("proof verification failed")
+
+// ErrProofNotConfigured is returned when the network profile lacks what is
+// needed to verify the allowed transfer addresses, such as
+// Network.TransferAgentParamsField.
+var ErrProofNotConfigured = errors.New("proof verification not configured")
+
+// ProofStatus describes how far chain data could be established.
+type ProofStatus int
+
+const (
+	// ProofFetchFailed means the data itself could not be fetched.
+	ProofFetchFailed ProofStatus = iota
+	// ProofUnverified means the data was fetched but could not be verified against a proof.
+	ProofUnverified
+	// ProofVerified means the data was verified against the block's AppHash.
+	ProofVerified
+	// ProofFailed means a proof was obtained but the data does not verify
+	// against it: the node returned inconsistent data.
+	ProofFailed
+)
+
+func (s ProofStatus) String() string {
+	switch s {
+	case ProofFetchFailed:
+		return "fetch-failed"
+	case ProofUnverified:
+		return "unverified"
+	case ProofVerified:
+		return "verified"
+	case ProofFailed:
+		return "failed"
+	}
+	return "unknown"
+}
+
+// AllowedTransferAddresses is the result of FetchVerifiedAllowedTransferAddresses.
+type AllowedTransferAddresses struct {
+	// Addresses is the allowed set. It is nil when Status is ProofFetchFailed.
+	Addresses map[string]bool
+	Status    ProofStatus
+	// Height is the block height the proof was made at, when verified.
+	Height int64
+	// Err explains why the result is not verified.
+	Err error
+}
+
+// FetchVerifiedAllowedTransferAddresses fetches the allowed transfer addresses via
+// the node's /chain-api/ proxy and verifies them against an ABCI proof of the
+// inference module's params, obtained via the node's /chain-rpc/ proxy, in the
+// same way GetParticipantsWithProof verifies participants.
+func FetchVerifiedAllowedTransferAddresses(ctx context.Context, nodeUrl string, network Network) (result AllowedTransferAddresses) {
+	ctx, span := startSpan(ctx, "gonka.FetchAllowedTransferAddresses", attribute.String("gonka.source_url", nodeUrl))
+	defer func() {
+		span.SetAttributes(attribute.String("gonka.proof_status", result.Status.String()))
+		endSpan(span, result.Err)
+		metricsFromContext(ctx).ObserveProof(ProofKindAllowedTransferAddresses, result.Status)
+	}()
+	params, err := chainclient.New(nodeUrl, chainclient.Options{}).Params(ctx)
+	if err != nil {
+		return AllowedTransferAddresses{Status: ProofFetchFailed, Err: err}
+	}
+	claimed := params.TransferAgentAccessParams.AllowedTransferAddresses
+	result = AllowedTransferAddresses{
+		Addresses: make(map[string]bool, len(claimed)),
+		Status:    ProofUnverified,
+	}
+	for _, a := range claimed {
+		result.Addresses[a] = true
+	}
+
+	if network.ParamsKey == "" || network.ProofStoreKey == "" || network.TransferAgentParamsField <= 0 {
+		result.Err = fmt.Errorf("%w: network %q has no params proof key or transfer agent params field", ErrProofNotConfigured, network.Name)
+		return result
+	}
+	value, proofOps, appHash, height, err := fetchParamsProof(ctx, nodeUrl, network)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	if err := verifyParamsProof(network, value, proofOps, appHash, claimed); err != nil {
+		result.Status = ProofFailed
+		result.Err = err
+		return result
+	}
+	result.Status = ProofVerified
+	result.Height = height
+	return result
+}
+
+// verifyParamsProof checks that value is proven under the params key and that it
+// contains exactly the claimed allowed transfer addresses.
+func verifyParamsProof(network Network, value []byte, proofOps []cryptotypes.ProofOp, appHash []byte, claimed []string) error {
+	if err := VerifyIAVLProofAgainstAppHash(appHash, proofOps, value); err != nil {
+		return fmt.Errorf("%w: %v", ErrProofVerification, err)
+	}
+	if key := string(proofOps[0].Key); key != network.ParamsKey {
+		return fmt.Errorf("%w: proof is for key %q, expected %q", ErrProofVerification, key, network.ParamsKey)
+	}
+	if store := string(proofOps[1].Key); store != network.ProofStoreKey {
+		return fmt.Errorf("%w: proof is for store %q, expected %q", ErrProofVerification, store, network.ProofStoreKey)
+	}
+	if !paramsContainAddressList(value, network.TransferAgentParamsField, claimed) {
+		return fmt.Errorf("%w: allowed transfer addresses do not match the proven params", ErrProofVerification)
+	}
+	return nil
+}
+
+// paramsContainAddressList reports whether the TransferAgentAccessParams at
+// the given field of the encoded params message have allowed_transfer_addresses
+// (field 1) that are exactly want.
+func paramsContainAddressList(params []byte, field int, want []string) bool {
+	want = append([]string(nil), want...)
+	sort.Strings(want)
+	var got []string
+	for len(params) > 0 {
+		num, typ, n := protowire.ConsumeTag(params)
+		if n < 0 {
+			return false
+		}
+		params = params[n:]
+		if int(num) != field {
+			n = protowire.ConsumeFieldValue(num, typ, params)
+			if n < 0 {
+				return false
+			}
+			params = params[n:]
+			continue
+		}
+		if typ != protowire.BytesType {
+			return false
+		}
+		msg, n := protowire.ConsumeBytes(params)
+		if n < 0 {
+			return false
+		}
+		params = params[n:]
+		// Repeated occurrences of a message field are merged when decoding
+		list, ok := stringList(msg)
+		if !ok {
+			return false
+		}
+		got = append(got, list...)
+	}
+	sort.Strings(got)
+	return equalStrings(got, want)
+}
+
+// stringList decodes a message made only of repeated string field 1.
+func stringList(msg []byte) ([]string, bool) {
+	var out []string
+	for len(msg) > 0 {
+		num, typ, n := protowire.ConsumeTag(msg)
+		if n < 0 || num != 1 || typ != protowire.BytesType {
+			return nil, false
+		}
+		msg = msg[n:]
+		s, n := protowire.ConsumeBytes(msg)
+		if n < 0 {
+			return nil, false
+		}
+		msg = msg[n:]
+		out = append(out, string(s))
+	}
+	return out, true
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// fetchParamsProof queries the params key with a proof and the AppHash of the
+// block that commits to it. It returns the proven value, the proof ops, the AppHash
+// and the query height.
+func fetchParamsProof(ctx context.Context, nodeUrl string, network Network) ([]byte, []cryptotypes.ProofOp, []byte, int64, error) {
+	base := strings.TrimRight(nodeUrl, "/")
+	if strings.HasSuffix(base, "/v1") {
+		base = base[:len(base)-3]
+	}
+	rpc := base + "/chain-rpc"
+
+	query := url.Values{}
+	query.Set("path", fmt.Sprintf("%q", "/store/"+network.ProofStoreKey+"/key"))
+	query.Set("data", "0x"+hex.EncodeToString([]byte(network.ParamsKey)))
+	query.Set("prove", "true")
+	var abci struct {
+		Result struct {
+			Response struct {
+				Code     uint32                `json:"code"`
+				Log      string                `json:"log"`
+				Value    []byte                `json:"value"`
+				ProofOps *cryptotypes.ProofOps `json:"proof_ops"`
+				Height   chainclient.Int64     `json:"height"`
+			} `json:"response"`
+		} `json:"result"`
+	}
+	if err := getRPC(ctx, rpc+"/abci_query?"+query.Encode(), &abci); err != nil {
+		return nil, nil, nil, 0, fmt.Errorf("params proof query failed: %w", err)
+	}
+	r := abci.Result.Response
+	if r.Code != 0 {
+		return nil, nil, nil, 0, fmt.Errorf("params proof query failed with code %d: %s", r.Code, r.Log)
+	}
+	if r.ProofOps == nil || len(r.Value) == 0 {
+		return nil, nil, nil, 0, fmt.Errorf("params proof query returned no proof")
+	}
+
+	// The AppHash committing to state at height H is in the header of block H+1
+	height := int64(r.Height)
+	var block struct {
+		Result struct {
+			Block struct {
+				Header struct {
+					AppHash cmtbytes.HexBytes `json:"app_hash"`
+				} `json:"header"`
+			} `json:"block"`
+		} `json:"result"`
+	}
+	if err := getRPC(ctx, rpc+"/block?height="+strconv.FormatInt(height+1, 10), &block); err != nil {
+		return nil, nil, nil, 0, fmt.Errorf("failed to fetch block %d: %w", height+1, err)
+	}
+	return r.Value, r.ProofOps.Ops, block.Result.Block.Header.AppHash, height, nil
+}
+
+func getRPC(ctx context.Context, u string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("request failed with status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, out)
+}
@@ -0,0 +1,144 @@
+package gonkaopenai
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	cryptotypes "github.com/cometbft/cometbft/proto/tendermint/crypto"
+	"github.com/cosmos/gogoproto/proto"
+	ics23 "github.com/cosmos/ics23/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// encodeParams encodes a params message with an unrelated field and the
+// transfer agent access params at field 7.
+func encodeParams(addresses ...string) []byte {
+	var agent []byte
+	for _, a := range addresses {
+		agent = protowire.AppendTag(agent, 1, protowire.BytesType)
+		agent = protowire.AppendString(agent, a)
+	}
+	var other []byte
+	other = protowire.AppendTag(other, 1, protowire.VarintType)
+	other = protowire.AppendVarint(other, 42)
+
+	var params []byte
+	params = protowire.AppendTag(params, 1, protowire.BytesType)
+	params = protowire.AppendBytes(params, other)
+	params = protowire.AppendTag(params, 7, protowire.BytesType)
+	params = protowire.AppendBytes(params, agent)
+	return params
+}
+
+// buildProof builds single-leaf IAVL and simple proofs for key → value in store,
+// returning the proof ops and the resulting AppHash.
+func buildProof(t *testing.T, store, key string, value []byte) ([]cryptotypes.ProofOp, []byte) {
+	t.Helper()
+	iavlLeaf := *ics23.IavlSpec.LeafSpec
+	iavlLeaf.Prefix = []byte{0, 2, 2} // height 0, size 1, version 1
+	iavl := &ics23.CommitmentProof{Proof: &ics23.CommitmentProof_Exist{Exist: &ics23.ExistenceProof{
+		Key: []byte(key), Value: value, Leaf: &iavlLeaf,
+	}}}
+	storeRoot, err := iavl.Calculate()
+	require.NoError(t, err)
+
+	simple := &ics23.CommitmentProof{Proof: &ics23.CommitmentProof_Exist{Exist: &ics23.ExistenceProof{
+		Key: []byte(store), Value: storeRoot, Leaf: ics23.TendermintSpec.LeafSpec,
+	}}}
+	appHash, err := simple.Calculate()
+	require.NoError(t, err)
+
+	iavlData, err := proto.Marshal(iavl)
+	require.NoError(t, err)
+	simpleData, err := proto.Marshal(simple)
+	require.NoError(t, err)
+	return []cryptotypes.ProofOp{
+		{Type: "ics23:iavl", Key: []byte(key), Data: iavlData},
+		{Type: "ics23:simple", Key: []byte(store), Data: simpleData},
+	}, appHash
+}
+
+func TestParamsContainAddressList(t *testing.T) {
+	params := encodeParams("gonka1a", "gonka1b")
+	assert.True(t, paramsContainAddressList(params, 7, []string{"gonka1b", "gonka1a"}))
+	assert.False(t, paramsContainAddressList(params, 3, []string{"gonka1a", "gonka1b"}))
+	assert.False(t, paramsContainAddressList(params, 7, []string{"gonka1a"}))
+	assert.False(t, paramsContainAddressList(params, 7, []string{"gonka1a", "gonka1b", "gonka1c"}))
+	// A list at another field does not count
+	assert.False(t, paramsContainAddressList(params, 1, []string{"gonka1a", "gonka1b"}))
+}
+
+func TestFetchVerifiedAllowedTransferAddresses(t *testing.T) {
+	value := encodeParams(testTransferAddress)
+	ops, appHash := buildProof(t, "inference", "p_inference", value)
+	claimed := testTransferAddress
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/chain-api/productscience/inference/inference/params"):
+			w.Write([]byte(`{"params":{"transfer_agent_access_params":{"allowed_transfer_addresses":["` + claimed + `"]}}}`))
+		case strings.HasSuffix(r.URL.Path, "/chain-rpc/abci_query"):
+			assert.Equal(t, "0x"+hex.EncodeToString([]byte("p_inference")), r.URL.Query().Get("data"))
+			opsJSON, _ := json.Marshal(ops)
+			w.Write([]byte(`{"result":{"response":{"code":0,"value":"` + base64.StdEncoding.EncodeToString(value) +
+				`","proof_ops":{"ops":` + string(opsJSON) + `},"height":"100"}}}`))
+		case strings.HasSuffix(r.URL.Path, "/chain-rpc/block"):
+			assert.Equal(t, "101", r.URL.Query().Get("height"))
+			w.Write([]byte(`{"result":{"block":{"header":{"app_hash":"` + strings.ToUpper(hex.EncodeToString(appHash)) + `"}}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	network := Testnet
+	network.TransferAgentParamsField = 7
+	res := FetchVerifiedAllowedTransferAddresses(context.Background(), srv.URL, network)
+	require.NoError(t, res.Err)
+	assert.Equal(t, ProofVerified, res.Status)
+	assert.Equal(t, int64(100), res.Height)
+	assert.True(t, res.Addresses[testTransferAddress])
+
+	// A node that lies about the list fails verification.
+	claimed = "gonka1someoneelse"
+	res = FetchVerifiedAllowedTransferAddresses(context.Background(), srv.URL, network)
+	assert.Equal(t, ProofFailed, res.Status)
+	assert.ErrorIs(t, res.Err, ErrProofVerification)
+
+	// Without the params field the list cannot be bound to the proof
+	claimed = testTransferAddress
+	res = FetchVerifiedAllowedTransferAddresses(context.Background(), srv.URL, Testnet)
+	assert.Equal(t, ProofUnverified, res.Status)
+	assert.ErrorIs(t, res.Err, ErrProofNotConfigured)
+
+	res = FetchVerifiedAllowedTransferAddresses(context.Background(), "http://127.0.0.1:1", network)
+	assert.Equal(t, ProofFetchFailed, res.Status)
+	assert.Nil(t, res.Addresses)
+}
+
+func TestCheckAllowedTransferAddresses(t *testing.T) {
+	unconfigured := AllowedTransferAddresses{Status: ProofUnverified, Err: fmt.Errorf("%w: no field", ErrProofNotConfigured)}
+	unproven := AllowedTransferAddresses{Status: ProofUnverified, Err: errors.New("no proof returned")}
+
+	assert.NoError(t, checkAllowedTransferAddresses(AllowedTransferAddresses{Status: ProofVerified}, Mainnet))
+	assert.NoError(t, checkAllowedTransferAddresses(unproven, Testnet))
+	assert.Error(t, checkAllowedTransferAddresses(unproven, Mainnet))
+	// An unset params field does not block clients that require proofs
+	assert.NoError(t, checkAllowedTransferAddresses(unconfigured, Mainnet))
+	t.Setenv("GONKA_VERIFY_PROOF", "1")
+	assert.NoError(t, checkAllowedTransferAddresses(unconfigured, Testnet))
+
+	assert.ErrorIs(t, checkAllowedTransferAddresses(AllowedTransferAddresses{
+		Status: ProofFailed, Err: ErrProofVerification}, Testnet), ErrProofVerification)
+	assert.Error(t, checkAllowedTransferAddresses(AllowedTransferAddresses{Status: ProofFetchFailed}, Testnet))
+}
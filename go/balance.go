@@ -0,0 +1,184 @@
+package gonkaopenai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gonka-ai/gonka-openai/go/chainclient"
+)
+
+// ErrInsufficientBalance is returned for requests refused because the requester's
+// balance fell below BalanceWatcherOptions.RefuseBelow.
+var ErrInsufficientBalance = errors.New("insufficient balance")
+
+// DefaultBalanceInterval is how often a BalanceWatcher polls when no interval is set.
+const DefaultBalanceInterval = time.Minute
+
+// Balance returns the requester's balance in the chain's base denomination.
+func (g *GonkaOpenAI) Balance(ctx context.Context) (chainclient.Coin, error) {
+	return g.chain.Balance(ctx, g.GonkaAddress(), "")
+}
+
+// BalanceWatcherOptions configures WatchBalance.
+type BalanceWatcherOptions struct {
+	// Interval between balance polls. Defaults to DefaultBalanceInterval.
+	Interval time.Duration
+	// Denom to watch. Defaults to chainclient.DefaultDenom.
+	Denom string
+	// LowBalance is the threshold below which OnLowBalance fires.
+	LowBalance *big.Int
+	// OnLowBalance is called once when the balance drops below LowBalance, and
+	// again only after it has recovered above it.
+	OnLowBalance func(balance chainclient.Coin)
+	// RefuseBelow, if set, makes the client refuse new requests with
+	// ErrInsufficientBalance while the balance is below it. With several keys,
+	// requests are refused whichever key would sign them.
+	RefuseBelow *big.Int
+	// OnError is called when a balance poll fails.
+	OnError func(err error)
+}
+
+// BalanceWatcher periodically polls the requester's balance. Create one with WatchBalance.
+type BalanceWatcher struct {
+	opts    BalanceWatcherOptions
+	address string
+	chain   *chainclient.Client
+
+	mu      sync.RWMutex
+	balance chainclient.Coin
+	updated time.Time
+	spent   *big.Int
+	low     bool
+	refuse  bool
+
+	stop context.CancelFunc
+	done chan struct{}
+	// removeCheck removes the watcher's request check, if it added one.
+	removeCheck func()
+}
+
+// WatchBalance starts polling the requester's balance until ctx is cancelled or
+// Stop is called. The first poll happens immediately. The watcher is bound to
+// the address GonkaAddress returns when it starts: it keeps watching that
+// account after keys are rotated, so start a new watcher for the new key.
+func (g *GonkaOpenAI) WatchBalance(ctx context.Context, opts BalanceWatcherOptions) *BalanceWatcher {
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultBalanceInterval
+	}
+	if opts.Denom == "" {
+		opts.Denom = chainclient.DefaultDenom
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	w := &BalanceWatcher{
+		opts:    opts,
+		address: g.GonkaAddress(),
+		chain:   g.chain,
+		spent:   new(big.Int),
+		stop:    cancel,
+		done:    make(chan struct{}),
+	}
+	if opts.RefuseBelow != nil && g.checks != nil {
+		w.removeCheck = g.checks.add(w.check)
+	}
+	go w.run(ctx)
+	return w
+}
+
+func (w *BalanceWatcher) run(ctx context.Context) {
+	defer func() {
+		// A stopped watcher no longer refuses requests
+		w.mu.Lock()
+		w.refuse = false
+		w.mu.Unlock()
+		if w.removeCheck != nil {
+			w.removeCheck()
+		}
+		close(w.done)
+	}()
+	ticker := time.NewTicker(w.opts.Interval)
+	defer ticker.Stop()
+	for {
+		w.poll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *BalanceWatcher) poll(ctx context.Context) {
+	coin, err := w.chain.Balance(ctx, w.address, w.opts.Denom)
+	if err == nil && coin.Int() == nil {
+		err = fmt.Errorf("invalid balance amount %q", coin.Amount)
+	}
+	if err != nil {
+		if ctx.Err() == nil && w.opts.OnError != nil {
+			w.opts.OnError(err)
+		}
+		return
+	}
+	w.update(coin, time.Now())
+}
+
+// update records a new balance and fires the low-balance callback on a downward crossing.
+func (w *BalanceWatcher) update(coin chainclient.Coin, at time.Time) {
+	amount := coin.Int()
+
+	w.mu.Lock()
+	if prev := w.balance.Int(); prev != nil && amount.Cmp(prev) < 0 {
+		w.spent.Add(w.spent, new(big.Int).Sub(prev, amount))
+	}
+	w.balance = coin
+	w.updated = at
+	w.refuse = w.opts.RefuseBelow != nil && amount.Cmp(w.opts.RefuseBelow) < 0
+	wasLow := w.low
+	w.low = w.opts.LowBalance != nil && amount.Cmp(w.opts.LowBalance) < 0
+	fire := w.low && !wasLow
+	w.mu.Unlock()
+
+	if fire && w.opts.OnLowBalance != nil {
+		w.opts.OnLowBalance(coin)
+	}
+}
+
+// check is registered with the signing transport when RefuseBelow is set.
+func (w *BalanceWatcher) check(*http.Request) error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if !w.refuse {
+		return nil
+	}
+	return fmt.Errorf("%w: %s has %s%s, minimum is %s", ErrInsufficientBalance, w.address, w.balance.Amount, w.balance.Denom, w.opts.RefuseBelow)
+}
+
+// Balance returns the last polled balance and when it was fetched.
+// The time is zero if no poll has succeeded yet.
+func (w *BalanceWatcher) Balance() (chainclient.Coin, time.Time) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.balance, w.updated
+}
+
+// Spent returns the total of balance decreases observed since the watcher started.
+// Top-ups are not subtracted.
+func (w *BalanceWatcher) Spent() *big.Int {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return new(big.Int).Set(w.spent)
+}
+
+// Address returns the requester address the watcher polls.
+func (w *BalanceWatcher) Address() string { return w.address }
+
+// Stop stops polling and waits for the watcher to exit. The watcher's request
+// check is removed, so requests are no longer refused once it is stopped.
+func (w *BalanceWatcher) Stop() {
+	w.stop()
+	<-w.done
+}
@@ -0,0 +1,68 @@
+package gonkaopenai
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gonka-ai/gonka-openai/go/chainclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBalanceWatcher(t *testing.T) {
+	var amount atomic.Value
+	amount.Store("1000")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/chain-api/cosmos/bank/v1beta1/balances/") {
+			w.Write([]byte(`{"balance":{"denom":"ngonka","amount":"` + amount.Load().(string) + `"}}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"object":"list","data":[]}`))
+	}))
+	defer srv.Close()
+
+	g, err := NewGonkaOpenAI(Options{
+		GonkaPrivateKey: testPrivateKey,
+		Endpoints:       []Endpoint{{URL: srv.URL + "/v1", Address: testTransferAddress}},
+	})
+	require.NoError(t, err)
+
+	coin, err := g.Balance(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "1000", coin.Amount)
+
+	var lowCalls atomic.Int32
+	w := g.WatchBalance(context.Background(), BalanceWatcherOptions{
+		Interval:     10 * time.Millisecond,
+		LowBalance:   big.NewInt(500),
+		OnLowBalance: func(chainclient.Coin) { lowCalls.Add(1) },
+		RefuseBelow:  big.NewInt(500),
+	})
+	defer w.Stop()
+	assert.Equal(t, g.GonkaAddress(), w.Address())
+
+	assert.Eventually(t, func() bool { c, _ := w.Balance(); return c.Amount == "1000" }, time.Second, 5*time.Millisecond)
+	_, err = g.Models.List(context.Background())
+	require.NoError(t, err)
+
+	amount.Store("400")
+	assert.Eventually(t, func() bool { c, _ := w.Balance(); return c.Amount == "400" }, time.Second, 5*time.Millisecond)
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, int32(1), lowCalls.Load())
+	assert.Equal(t, "600", w.Spent().String())
+
+	_, err = g.Models.List(context.Background())
+	assert.ErrorIs(t, err, ErrInsufficientBalance)
+
+	w.Stop()
+	_, err = g.Models.List(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, g.checks.checks, "the stopped watcher's check is removed")
+}
@@ -0,0 +1,57 @@
+package gonkaopenai
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+)
+
+type callerTagKey struct{}
+
+// WithCallerTag returns a context whose requests are attributed to tag, such as
+// a team, service or end user. The tag is recorded in metrics, logs, hook
+// events, receipts and the usage ledger, and forwarded to participants as the
+// OpenAI "user" field if the client is configured to.
+func WithCallerTag(ctx context.Context, tag string) context.Context {
+	return context.WithValue(ctx, callerTagKey{}, tag)
+}
+
+// CallerTagFromContext returns the caller tag set with WithCallerTag.
+func CallerTagFromContext(ctx context.Context) (string, bool) {
+	tag, ok := ctx.Value(callerTagKey{}).(string)
+	return tag, ok
+}
+
+// userFieldPaths are the API paths whose requests accept the "user" field.
+var userFieldPaths = []string{"/chat/completions", "/completions", "/embeddings"}
+
+// withUserField returns payload with its "user" field set to tag, if path
+// accepts one and payload is a JSON object without one.
+func withUserField(path, payload, tag string) string {
+	if tag == "" {
+		return payload
+	}
+	accepted := false
+	for _, p := range userFieldPaths {
+		if strings.HasSuffix(path, p) {
+			accepted = true
+			break
+		}
+	}
+	if !accepted {
+		return payload
+	}
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(payload), &body); err != nil || body == nil {
+		return payload
+	}
+	if _, ok := body["user"]; ok {
+		return payload
+	}
+	body["user"], _ = json.Marshal(tag)
+	data, err := json.Marshal(body)
+	if err != nil {
+		return payload
+	}
+	return string(data)
+}
@@ -0,0 +1,75 @@
+package gonkaopenai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openai/openai-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithUserField(t *testing.T) {
+	assert.JSONEq(t, `{"model":"m","user":"search"}`, withUserField("/v1/chat/completions", `{"model":"m"}`, "search"))
+	assert.JSONEq(t, `{"model":"m","user":"me"}`, withUserField("/v1/chat/completions", `{"model":"m","user":"me"}`, "search"))
+	assert.Equal(t, `{"model":"m"}`, withUserField("/v1/chat/completions", `{"model":"m"}`, ""))
+	assert.Equal(t, `{"model":"m"}`, withUserField("/v1/models", `{"model":"m"}`, "search"))
+	assert.Equal(t, `[1]`, withUserField("/v1/embeddings", `[1]`, "search"))
+}
+
+func TestCallerTagAttribution(t *testing.T) {
+	var user string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var req struct {
+			User string `json:"user"`
+		}
+		_ = json.Unmarshal(body, &req)
+		user = req.User
+		// The signature covers the rewritten body
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		assert.NoError(t, VerifyRequest(r, nil, testTransferAddress))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"chatcmpl-1","object":"chat.completion","model":"m","choices":[],` +
+			`"usage":{"prompt_tokens":3,"completion_tokens":4,"total_tokens":7}}`))
+	}))
+	defer srv.Close()
+
+	var logs bytes.Buffer
+	metrics := &recordingMetrics{}
+	var signed RequestSignedEvent
+	var receipt InferenceReceipt
+	g, err := NewGonkaOpenAI(Options{
+		GonkaPrivateKey:  testPrivateKey,
+		Endpoints:        []Endpoint{{URL: srv.URL + "/v1", Address: testTransferAddress}},
+		ForwardCallerTag: true,
+		Metrics:          metrics,
+		Logger:           slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		Hooks:            Hooks{OnRequestSigned: func(e RequestSignedEvent) { signed = e }},
+		OnReceipt:        func(r InferenceReceipt) { receipt = r },
+	})
+	require.NoError(t, err)
+
+	ctx := WithCallerTag(context.Background(), "search")
+	_, err = g.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
+		Model:    "m",
+		Messages: []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hi")},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "search", user)
+	assert.Equal(t, "search", signed.CallerTag)
+	assert.Equal(t, "search", receipt.CallerTag)
+	assert.Contains(t, logs.String(), `"caller":"search"`)
+	assert.Equal(t, int64(7), g.Usage().Totals(UsageKey{CallerTag: "search"}).TotalTokens)
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	require.Len(t, metrics.requests, 1)
+	assert.Equal(t, "search", metrics.requests[0].CallerTag)
+}
@@ -0,0 +1,57 @@
+package chainclient
+
+import (
+	"context"
+	"math/big"
+	"net/url"
+)
+
+// DefaultDenom is the base denomination of the Gonka chain.
+const DefaultDenom = "ngonka"
+
+// Coin is an amount of a single denomination. Amount is a decimal integer string
+// because balances may exceed 64 bits.
+type Coin struct {
+	Denom  string `json:"denom"`
+	Amount string `json:"amount"`
+}
+
+// Int returns Amount as a big integer, or nil if it is not a valid integer.
+func (c Coin) Int() *big.Int {
+	v, ok := new(big.Int).SetString(c.Amount, 10)
+	if !ok {
+		return nil
+	}
+	return v
+}
+
+// Balances returns a page of the balances held by address.
+func (c *Client) Balances(ctx context.Context, address string, page *PageRequest) ([]Coin, *PageResponse, error) {
+	var resp struct {
+		Balances   []Coin        `json:"balances"`
+		Pagination *PageResponse `json:"pagination"`
+	}
+	if err := c.get(ctx, "/cosmos/bank/v1beta1/balances/"+url.PathEscape(address), page.values(), &resp); err != nil {
+		return nil, nil, err
+	}
+	return resp.Balances, resp.Pagination, nil
+}
+
+// Balance returns the balance of a single denomination held by address.
+// An empty denom selects DefaultDenom.
+func (c *Client) Balance(ctx context.Context, address, denom string) (Coin, error) {
+	if denom == "" {
+		denom = DefaultDenom
+	}
+	var resp struct {
+		Balance Coin `json:"balance"`
+	}
+	query := url.Values{"denom": []string{denom}}
+	if err := c.get(ctx, "/cosmos/bank/v1beta1/balances/"+url.PathEscape(address)+"/by_denom", query, &resp); err != nil {
+		return Coin{}, err
+	}
+	if resp.Balance.Denom == "" {
+		resp.Balance = Coin{Denom: denom, Amount: "0"}
+	}
+	return resp.Balance, nil
+}
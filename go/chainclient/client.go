@@ -0,0 +1,192 @@
+// Package chainclient is a typed REST client for the Gonka chain, reached through
+// a node's /chain-api/ proxy. It covers the queries of the inference module and
+// the bank balances of accounts.
+package chainclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout bounds each query when the context has no earlier deadline.
+const DefaultTimeout = 30 * time.Second
+
+// inferencePath is the REST prefix of the inference module's queries.
+const inferencePath = "/productscience/inference/inference"
+
+// ErrNotFound is returned when the queried object does not exist on chain.
+var ErrNotFound = errors.New("not found")
+
+// APIError is returned for non-200 responses from the chain API.
+// It matches ErrNotFound with errors.Is when the object does not exist.
+type APIError struct {
+	StatusCode int
+	// Code is the gRPC status code reported by the chain, if any.
+	Code    int
+	Message string
+	Path    string
+}
+
+func (e *APIError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("chain api %s: status %d: %s", e.Path, e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("chain api %s: status %d", e.Path, e.StatusCode)
+}
+
+// Is reports whether the error means the object was not found.
+func (e *APIError) Is(target error) bool {
+	// gRPC code 5 is NotFound
+	return target == ErrNotFound && (e.StatusCode == http.StatusNotFound || e.Code == 5)
+}
+
+// Options configures a Client.
+type Options struct {
+	// HTTPClient is used for requests. Defaults to a new http.Client.
+	HTTPClient *http.Client
+	// Timeout bounds each query. Defaults to DefaultTimeout.
+	Timeout time.Duration
+}
+
+// Client queries the chain through a node's /chain-api/ proxy.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	timeout    time.Duration
+}
+
+// New creates a Client for the node at nodeUrl. A trailing /v1 is ignored, so an
+// inference endpoint URL can be passed as is.
+func New(nodeUrl string, opts Options) *Client {
+	base := strings.TrimRight(nodeUrl, "/")
+	if strings.HasSuffix(base, "/v1") {
+		base = base[:len(base)-3]
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = &http.Client{}
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	return &Client{
+		baseURL:    base + "/chain-api",
+		httpClient: opts.HTTPClient,
+		timeout:    opts.Timeout,
+	}
+}
+
+// get performs a GET request for path with the given query and decodes the JSON response into out.
+func (c *Client) get(ctx context.Context, path string, query url.Values, out any) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		apiErr := &APIError{StatusCode: resp.StatusCode, Path: path}
+		var status struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(body, &status) == nil {
+			apiErr.Code = status.Code
+			apiErr.Message = status.Message
+		}
+		return apiErr
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("chain api %s: failed to decode response: %w", path, err)
+	}
+	return nil
+}
+
+// PageRequest selects a page of a list query. A nil *PageRequest returns the first page
+// with the chain's default limit.
+type PageRequest struct {
+	// Key is the NextKey of the previous page.
+	Key   string
+	Limit uint64
+	// CountTotal asks the chain to fill PageResponse.Total.
+	CountTotal bool
+}
+
+func (p *PageRequest) values() url.Values {
+	q := url.Values{}
+	if p == nil {
+		return q
+	}
+	if p.Key != "" {
+		q.Set("pagination.key", p.Key)
+	}
+	if p.Limit > 0 {
+		q.Set("pagination.limit", strconv.FormatUint(p.Limit, 10))
+	}
+	if p.CountTotal {
+		q.Set("pagination.count_total", "true")
+	}
+	return q
+}
+
+// PageResponse describes the position of a page in a list.
+type PageResponse struct {
+	// NextKey is empty on the last page.
+	NextKey string `json:"next_key"`
+	Total   Uint64 `json:"total"`
+}
+
+// Int64 decodes an int64 sent either as a JSON number or, as the chain does, as a string.
+type Int64 int64
+
+func (i *Int64) UnmarshalJSON(b []byte) error {
+	s := strings.Trim(string(b), `"`)
+	if s == "" || s == "null" {
+		*i = 0
+		return nil
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return err
+	}
+	*i = Int64(v)
+	return nil
+}
+
+// Uint64 decodes a uint64 sent either as a JSON number or, as the chain does, as a string.
+type Uint64 uint64
+
+func (u *Uint64) UnmarshalJSON(b []byte) error {
+	s := strings.Trim(string(b), `"`)
+	if s == "" || s == "null" {
+		*u = 0
+		return nil
+	}
+	v, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return err
+	}
+	*u = Uint64(v)
+	return nil
+}
@@ -0,0 +1,96 @@
+package chainclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestServer(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return New(srv.URL+"/v1/", Options{})
+}
+
+func TestParams(t *testing.T) {
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/chain-api/productscience/inference/inference/params", r.URL.Path)
+		w.Write([]byte(`{"params":{"transfer_agent_access_params":{"allowed_transfer_addresses":["gonka1a","gonka1b"]}}}`))
+	})
+	params, err := c.Params(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gonka1a", "gonka1b"}, params.TransferAgentAccessParams.AllowedTransferAddresses)
+}
+
+func TestAllParticipantsPaginates(t *testing.T) {
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("pagination.key") {
+		case "":
+			w.Write([]byte(`{"participant":[{"index":"gonka1a","weight":"10"}],"pagination":{"next_key":"page2","total":"2"}}`))
+		case "page2":
+			w.Write([]byte(`{"participant":[{"index":"gonka1b","weight":20}],"pagination":{"next_key":null}}`))
+		}
+	})
+	participants, err := c.AllParticipants(context.Background())
+	require.NoError(t, err)
+	require.Len(t, participants, 2)
+	assert.Equal(t, Int64(10), participants[0].Weight)
+	assert.Equal(t, "gonka1b", participants[1].Index)
+	assert.Equal(t, Int64(20), participants[1].Weight)
+}
+
+func TestInferenceNotFound(t *testing.T) {
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"code":5,"message":"key not found","details":[]}`))
+	})
+	_, err := c.Inference(context.Background(), "abc")
+	assert.ErrorIs(t, err, ErrNotFound)
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "key not found", apiErr.Message)
+}
+
+func TestModelPerTokenPrice(t *testing.T) {
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/chain-api/productscience/inference/inference/get_model_per_token_price/Qwen%2FQwQ-32B":
+			w.Write([]byte(`{"price":"250","found":true}`))
+		default:
+			w.Write([]byte(`{"price":"0","found":false}`))
+		}
+	})
+	price, err := c.ModelPerTokenPrice(context.Background(), "Qwen/QwQ-32B")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(250), price)
+
+	_, err = c.ModelPerTokenPrice(context.Background(), "unknown")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestBalance(t *testing.T) {
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/chain-api/cosmos/bank/v1beta1/balances/gonka1a/by_denom", r.URL.Path)
+		assert.Equal(t, DefaultDenom, r.URL.Query().Get("denom"))
+		w.Write([]byte(`{"balance":{"denom":"ngonka","amount":"123456789012345678901"}}`))
+	})
+	coin, err := c.Balance(context.Background(), "gonka1a", "")
+	require.NoError(t, err)
+	assert.Equal(t, "123456789012345678901", coin.Int().String())
+}
+
+func TestTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+	c := New(srv.URL, Options{Timeout: 50 * time.Millisecond})
+	_, err := c.CurrentEpoch(context.Background())
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,164 @@
+package chainclient
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// Params are the inference module parameters. Only the sections used by clients are decoded.
+type Params struct {
+	TransferAgentAccessParams TransferAgentAccessParams `json:"transfer_agent_access_params"`
+}
+
+// TransferAgentAccessParams lists the transfer agents that may accept requests.
+type TransferAgentAccessParams struct {
+	AllowedTransferAddresses []string `json:"allowed_transfer_addresses"`
+}
+
+// Participant is a participant registered in the inference module.
+type Participant struct {
+	Index             string `json:"index"`
+	Address           string `json:"address"`
+	Weight            Int64  `json:"weight"`
+	JoinTime          Int64  `json:"join_time"`
+	JoinHeight        Int64  `json:"join_height"`
+	LastInferenceTime Int64  `json:"last_inference_time"`
+	InferenceUrl      string `json:"inference_url"`
+	Status            string `json:"status"`
+	CoinBalance       Int64  `json:"coin_balance"`
+	EpochsCompleted   Uint64 `json:"epochs_completed"`
+}
+
+// Inference is an inference recorded on chain.
+type Inference struct {
+	Index                string `json:"index"`
+	InferenceId          string `json:"inference_id"`
+	Model                string `json:"model"`
+	Status               string `json:"status"`
+	PromptHash           string `json:"prompt_hash"`
+	ResponseHash         string `json:"response_hash"`
+	PromptTokenCount     Uint64 `json:"prompt_token_count"`
+	CompletionTokenCount Uint64 `json:"completion_token_count"`
+	RequestedBy          string `json:"requested_by"`
+	ExecutedBy           string `json:"executed_by"`
+	TransferredBy        string `json:"transferred_by"`
+	StartBlockHeight     Int64  `json:"start_block_height"`
+	EndBlockHeight       Int64  `json:"end_block_height"`
+	StartBlockTimestamp  Int64  `json:"start_block_timestamp"`
+	EndBlockTimestamp    Int64  `json:"end_block_timestamp"`
+	MaxTokens            Uint64 `json:"max_tokens"`
+	ActualCost           Int64  `json:"actual_cost"`
+	EscrowAmount         Int64  `json:"escrow_amount"`
+	RequestTimestamp     Int64  `json:"request_timestamp"`
+	EpochId              Uint64 `json:"epoch_id"`
+}
+
+// Params returns the current inference module parameters.
+func (c *Client) Params(ctx context.Context) (*Params, error) {
+	var resp struct {
+		Params Params `json:"params"`
+	}
+	if err := c.get(ctx, inferencePath+"/params", nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Params, nil
+}
+
+// Participant returns the participant with the given index (its account address).
+func (c *Client) Participant(ctx context.Context, index string) (*Participant, error) {
+	var resp struct {
+		Participant Participant `json:"participant"`
+	}
+	if err := c.get(ctx, inferencePath+"/participant/"+url.PathEscape(index), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Participant, nil
+}
+
+// Participants returns a page of registered participants.
+func (c *Client) Participants(ctx context.Context, page *PageRequest) ([]Participant, *PageResponse, error) {
+	var resp struct {
+		Participant []Participant `json:"participant"`
+		Pagination  *PageResponse `json:"pagination"`
+	}
+	if err := c.get(ctx, inferencePath+"/participant", page.values(), &resp); err != nil {
+		return nil, nil, err
+	}
+	return resp.Participant, resp.Pagination, nil
+}
+
+// AllParticipants pages through all registered participants.
+func (c *Client) AllParticipants(ctx context.Context) ([]Participant, error) {
+	return collect(ctx, func(ctx context.Context, page *PageRequest) ([]Participant, *PageResponse, error) {
+		return c.Participants(ctx, page)
+	})
+}
+
+// CurrentEpoch returns the index of the current epoch.
+func (c *Client) CurrentEpoch(ctx context.Context) (uint64, error) {
+	var resp struct {
+		Epoch Uint64 `json:"epoch"`
+	}
+	if err := c.get(ctx, inferencePath+"/get_current_epoch", nil, &resp); err != nil {
+		return 0, err
+	}
+	return uint64(resp.Epoch), nil
+}
+
+// ModelPerTokenPrice returns the current price of one token of model, in the
+// chain's base denomination. It returns an error matching ErrNotFound if the
+// chain has no price for the model.
+func (c *Client) ModelPerTokenPrice(ctx context.Context, model string) (uint64, error) {
+	var resp struct {
+		Price Uint64 `json:"price"`
+		Found bool   `json:"found"`
+	}
+	if err := c.get(ctx, inferencePath+"/get_model_per_token_price/"+url.PathEscape(model), nil, &resp); err != nil {
+		return 0, err
+	}
+	if !resp.Found {
+		return 0, fmt.Errorf("%w: no price for model %q", ErrNotFound, model)
+	}
+	return uint64(resp.Price), nil
+}
+
+// Inference returns the inference with the given index (its inference ID).
+func (c *Client) Inference(ctx context.Context, index string) (*Inference, error) {
+	var resp struct {
+		Inference Inference `json:"inference"`
+	}
+	if err := c.get(ctx, inferencePath+"/inference/"+url.PathEscape(index), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Inference, nil
+}
+
+// Inferences returns a page of recorded inferences.
+func (c *Client) Inferences(ctx context.Context, page *PageRequest) ([]Inference, *PageResponse, error) {
+	var resp struct {
+		Inference  []Inference   `json:"inference"`
+		Pagination *PageResponse `json:"pagination"`
+	}
+	if err := c.get(ctx, inferencePath+"/inference", page.values(), &resp); err != nil {
+		return nil, nil, err
+	}
+	return resp.Inference, resp.Pagination, nil
+}
+
+// collect calls list until the last page and concatenates the results.
+func collect[T any](ctx context.Context, list func(context.Context, *PageRequest) ([]T, *PageResponse, error)) ([]T, error) {
+	var all []T
+	page := &PageRequest{}
+	for {
+		items, pr, err := list(ctx, page)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, items...)
+		if pr == nil || pr.NextKey == "" {
+			return all, nil
+		}
+		page = &PageRequest{Key: pr.NextKey}
+	}
+}
@@ -0,0 +1,191 @@
+package gonkaopenai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrClockSkew is returned when the local clock differs from the participant's
+// clock by more than the configured tolerance.
+var ErrClockSkew = errors.New("clock skew exceeds tolerance")
+
+// ClockSkewError reports the measured offset between the local clock and a participant.
+// It matches ErrClockSkew with errors.Is.
+type ClockSkewError struct {
+	// Offset is how far the participant's clock is ahead of the local clock.
+	Offset    time.Duration
+	Tolerance time.Duration
+}
+
+func (e *ClockSkewError) Error() string {
+	return fmt.Sprintf("%v: local clock is off by %s from the participant (tolerance %s); check the system time", ErrClockSkew, -e.Offset, e.Tolerance)
+}
+
+func (e *ClockSkewError) Unwrap() error { return ErrClockSkew }
+
+// DefaultClockSkewTolerance is the largest clock offset that is corrected automatically.
+// Larger offsets are corrected by the tolerance only.
+const DefaultClockSkewTolerance = 5 * time.Minute
+
+// Date headers only have second resolution, so offsets below this are treated as noise.
+const clockSkewDeadband = 2 * time.Second
+
+// clockSkew tracks the offset between the local clock and each participant's
+// clock, measured from Date response headers, and corrects the timestamps of
+// requests to that participant by it. Date headers are unauthenticated, so a
+// participant's offset only affects requests to that participant, and only by
+// up to the tolerance. A nil *clockSkew performs no correction.
+type clockSkew struct {
+	tolerance time.Duration
+
+	mu           sync.Mutex
+	participants map[string]*participantClock
+	// last is the most recent measurement of any participant.
+	last     time.Duration
+	measured bool
+}
+
+type participantClock struct {
+	offset time.Duration
+	lastTs int64
+}
+
+func newClockSkew(tolerance time.Duration) *clockSkew {
+	if tolerance < 0 {
+		return nil
+	}
+	if tolerance == 0 {
+		tolerance = DefaultClockSkewTolerance
+	}
+	return &clockSkew{tolerance: tolerance, participants: make(map[string]*participantClock)}
+}
+
+// Offset returns the most recent offset measured from any participant and
+// whether one has been measured.
+func (c *clockSkew) Offset() (time.Duration, bool) {
+	if c == nil {
+		return 0, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.last, c.measured
+}
+
+// check returns a *ClockSkewError if the offset measured from the participant
+// at endpoint exceeds the tolerance.
+func (c *clockSkew) check(endpoint string) error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	p, ok := c.participants[endpoint]
+	c.mu.Unlock()
+	if !ok || (p.offset <= c.tolerance && p.offset >= -c.tolerance) {
+		return nil
+	}
+	return &ClockSkewError{Offset: p.offset, Tolerance: c.tolerance}
+}
+
+// apply corrects a signing timestamp for the participant at endpoint by its
+// offset, clamped to the tolerance, keeping the participant's timestamps
+// strictly increasing when the estimate changes.
+func (c *clockSkew) apply(endpoint string, ts int64) int64 {
+	if c == nil {
+		return ts
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p := c.participant(endpoint)
+	offset := max(min(p.offset, c.tolerance), -c.tolerance)
+	if offset > clockSkewDeadband || offset < -clockSkewDeadband {
+		ts += int64(offset)
+	}
+	if ts <= p.lastTs {
+		ts = p.lastTs + 1
+	}
+	p.lastTs = ts
+	return ts
+}
+
+// observe updates the estimate for the participant at endpoint from the Date
+// header of a response to a request sent at start and answered at end. It
+// returns the measured offset.
+func (c *clockSkew) observe(endpoint string, start, end time.Time, date string) (time.Duration, bool) {
+	if c == nil {
+		return 0, false
+	}
+	offset, ok := offsetFromDate(start, end, date)
+	if !ok {
+		return 0, false
+	}
+	c.set(endpoint, offset)
+	return offset, true
+}
+
+// set records a new offset estimate for the participant at endpoint.
+func (c *clockSkew) set(endpoint string, offset time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.participant(endpoint).offset = offset
+	c.last = offset
+	c.measured = true
+}
+
+// participant returns the state of the participant at endpoint. c.mu must be held.
+func (c *clockSkew) participant(endpoint string) *participantClock {
+	p, ok := c.participants[endpoint]
+	if !ok {
+		p = &participantClock{}
+		c.participants[endpoint] = p
+	}
+	return p
+}
+
+// offsetFromDate compares a Date header with the midpoint of the request round trip.
+func offsetFromDate(start, end time.Time, date string) (time.Duration, bool) {
+	if date == "" {
+		return 0, false
+	}
+	serverTime, err := http.ParseTime(date)
+	if err != nil {
+		return 0, false
+	}
+	// Date is truncated to the second; assume the server time was mid-second.
+	serverTime = serverTime.Add(500 * time.Millisecond)
+	// Measure against the same clock the timestamp source is based on.
+	local := wallBase.Add(start.Sub(wallBase) + end.Sub(start)/2)
+	return serverTime.Sub(local), true
+}
+
+// MeasureClockSkew calls the node's identity endpoint and returns how far the
+// node's clock, as reported in its Date header, is ahead of the local clock.
+func MeasureClockSkew(ctx context.Context, nodeUrl string) (time.Duration, error) {
+	base := strings.TrimRight(nodeUrl, "/")
+	if strings.HasSuffix(base, "/v1") {
+		base = base[:len(base)-3]
+	}
+	url := base + "/v1/identity"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	end := time.Now()
+	resp.Body.Close()
+	offset, ok := offsetFromDate(start, end, resp.Header.Get("Date"))
+	if !ok {
+		return 0, fmt.Errorf("identity response from %s has no valid Date header", nodeUrl)
+	}
+	return offset, nil
+}
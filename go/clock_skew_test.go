@@ -0,0 +1,81 @@
+package gonkaopenai
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSkewedServer(t *testing.T, offset time.Duration, status int, timestamps *[]int64) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ts, _ := strconv.ParseInt(r.Header.Get("X-Timestamp"), 10, 64)
+		*timestamps = append(*timestamps, ts)
+		w.Header().Set("Date", time.Now().Add(offset).UTC().Format(http.TimeFormat))
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestClockSkew_Corrects(t *testing.T) {
+	var timestamps []int64
+	srv := newSkewedServer(t, 30*time.Second, http.StatusOK, &timestamps)
+	client, err := GonkaHTTPClient(HTTPClientOptions{
+		PrivateKey: testPrivateKey,
+		Endpoints:  []Endpoint{{URL: srv.URL + "/v1", Address: testTransferAddress}},
+	})
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Post(srv.URL+"/v1/chat/completions", "application/json", strings.NewReader(`{}`))
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+	require.Len(t, timestamps, 2)
+	// The second request is signed with the participant's time.
+	assert.InDelta(t, time.Now().Add(30*time.Second).UnixNano(), timestamps[1], float64(2*time.Second))
+}
+
+func TestClockSkew_ExceedsTolerance(t *testing.T) {
+	var timestamps []int64
+	srv := newSkewedServer(t, 10*time.Minute, http.StatusUnauthorized, &timestamps)
+	client, err := GonkaHTTPClient(HTTPClientOptions{
+		PrivateKey:         testPrivateKey,
+		Endpoints:          []Endpoint{{URL: srv.URL + "/v1", Address: testTransferAddress}},
+		ClockSkewTolerance: time.Minute,
+	})
+	require.NoError(t, err)
+
+	_, err = client.Post(srv.URL+"/v1/chat/completions", "application/json", strings.NewReader(`{}`))
+	assert.ErrorIs(t, err, ErrClockSkew)
+
+	// Later requests are still sent, so the offset is measured again.
+	_, err = client.Post(srv.URL+"/v1/chat/completions", "application/json", strings.NewReader(`{}`))
+	assert.ErrorIs(t, err, ErrClockSkew)
+	require.Len(t, timestamps, 2)
+	// The correction is clamped to the tolerance.
+	assert.InDelta(t, time.Now().Add(time.Minute).UnixNano(), timestamps[1], float64(2*time.Second))
+}
+
+func TestClockSkew_PerParticipant(t *testing.T) {
+	c := newClockSkew(time.Minute)
+	c.set("https://a.test/v1", time.Hour)
+	now := time.Now().UnixNano()
+
+	// Another participant's offset does not shift timestamps
+	assert.Equal(t, now, c.apply("https://b.test/v1", now))
+	assert.Equal(t, now+int64(time.Minute), c.apply("https://a.test/v1", now))
+	assert.ErrorIs(t, c.check("https://a.test/v1"), ErrClockSkew)
+	assert.NoError(t, c.check("https://b.test/v1"))
+
+	offset, ok := c.Offset()
+	assert.True(t, ok)
+	assert.Equal(t, time.Hour, offset)
+}
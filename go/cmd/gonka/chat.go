@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/openai/openai-go"
+)
+
+const chatHelp = `Type a message and press enter. Commands:
+  /reset    forget the conversation
+  /history  print the conversation
+  /exit     quit (or end the input)
+`
+
+// runChat runs an interactive chat, streaming each reply and keeping the
+// conversation as context for the next message.
+func runChat(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("chat", flag.ContinueOnError)
+	var client clientFlags
+	var model modelFlags
+	client.register(fs)
+	model.register(fs)
+	if err := parseFlags(fs, args, stderr); err != nil {
+		return err
+	}
+	g, err := client.newClient(stderr)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "Chatting with %s as %s.\n%s", model.model, g.GonkaAddress(), chatHelp)
+	var history []openai.ChatCompletionMessageParamUnion
+	var transcript []string
+	in := bufio.NewScanner(stdin)
+	in.Buffer(make([]byte, 0, 64<<10), 1<<20)
+	for {
+		fmt.Fprint(stdout, "> ")
+		if !in.Scan() {
+			fmt.Fprintln(stdout)
+			return in.Err()
+		}
+		line := strings.TrimSpace(in.Text())
+		switch line {
+		case "":
+			continue
+		case "/exit", "/quit":
+			return nil
+		case "/reset":
+			history, transcript = nil, nil
+			continue
+		case "/history":
+			for _, t := range transcript {
+				fmt.Fprintln(stdout, t)
+			}
+			continue
+		}
+
+		reply, err := streamReply(ctx, g.Chat.Completions, model.params(append(history, openai.UserMessage(line))), stdout)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			// The message is left out of the conversation so that it can be sent again
+			fmt.Fprintf(stderr, "error: %v\n", err)
+			continue
+		}
+		history = append(history, openai.UserMessage(line), reply.ToParam())
+		transcript = append(transcript, "user: "+line, "assistant: "+reply.Content)
+	}
+}
+
+// streamReply streams the reply to params to w and returns it.
+func streamReply(ctx context.Context, completions openai.ChatCompletionService, params openai.ChatCompletionNewParams, w io.Writer) (openai.ChatCompletionMessage, error) {
+	stream := completions.NewStreaming(ctx, params)
+	defer stream.Close()
+	var acc openai.ChatCompletionAccumulator
+	for stream.Next() {
+		chunk := stream.Current()
+		acc.AddChunk(chunk)
+		if len(chunk.Choices) > 0 {
+			fmt.Fprint(w, chunk.Choices[0].Delta.Content)
+		}
+	}
+	fmt.Fprintln(w)
+	if err := stream.Err(); err != nil {
+		return openai.ChatCompletionMessage{}, err
+	}
+	if len(acc.Choices) == 0 {
+		return openai.ChatCompletionMessage{}, fmt.Errorf("the reply has no choices")
+	}
+	return acc.Choices[0].Message, nil
+}
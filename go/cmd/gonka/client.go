@@ -0,0 +1,106 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	gonkaopenai "github.com/gonka-ai/gonka-openai/go"
+	"github.com/openai/openai-go"
+)
+
+// defaultModel is the model used when --model is not given.
+const defaultModel = "Qwen/QwQ-32B"
+
+// clientFlags configure the client. Unset flags fall back to the GONKA_*
+// environment variables, as read by gonkaopenai.NewGonkaOpenAI.
+type clientFlags struct {
+	privateKeyFile string
+	address        string
+	sourceURL      string
+	endpoints      string
+	network        string
+	verbose        bool
+	debug          bool
+}
+
+func (f *clientFlags) register(fs *flag.FlagSet) {
+	// The key is read from a file rather than a flag, which would expose it in
+	// the process list and shell history.
+	fs.StringVar(&f.privateKeyFile, "private-key-file", "", "file holding the hex private key to sign requests with (default $"+gonkaopenai.EnvPrivateKey+")")
+	fs.StringVar(&f.address, "address", "", "requester address (default $"+gonkaopenai.EnvAddress+" or the key's address)")
+	fs.StringVar(&f.sourceURL, "source-url", "", "node to discover participants from (default $"+gonkaopenai.EnvSourceUrl+")")
+	fs.StringVar(&f.endpoints, "endpoints", "", `endpoints as "url;address, url;address" (default $`+gonkaopenai.EnvEndpoints+")")
+	fs.StringVar(&f.network, "network", "", "network name or chain id (default $"+gonkaopenai.EnvNetwork+")")
+	fs.BoolVar(&f.verbose, "v", false, "log discovery and signing to stderr")
+	fs.BoolVar(&f.debug, "debug", false, "dump signed requests and responses to stderr")
+}
+
+// newClient creates the client the flags describe, logging to stderr.
+func (f *clientFlags) newClient(stderr io.Writer) (*gonkaopenai.GonkaOpenAI, error) {
+	opts := gonkaopenai.Options{
+		GonkaAddress: f.address,
+		SourceUrl:    f.sourceURL,
+		Debug:        f.debug,
+		DebugWriter:  stderr,
+	}
+	if f.privateKeyFile != "" {
+		key, err := os.ReadFile(f.privateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("--private-key-file: %w", err)
+		}
+		opts.GonkaPrivateKey = strings.TrimSpace(string(key))
+	}
+	if f.endpoints != "" {
+		endpoints, err := gonkaopenai.ParseEndpoints(f.endpoints)
+		if err != nil {
+			return nil, fmt.Errorf("--endpoints: %w", err)
+		}
+		opts.Endpoints = endpoints
+	}
+	if f.network != "" {
+		network, ok := gonkaopenai.NetworkByName(f.network)
+		if !ok {
+			return nil, fmt.Errorf("--network: unknown network %q", f.network)
+		}
+		opts.Network = network
+	}
+	if f.verbose {
+		opts.Logger = slog.New(slog.NewTextHandler(stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	}
+	return gonkaopenai.NewGonkaOpenAI(opts)
+}
+
+// modelFlags configure the completion requests.
+type modelFlags struct {
+	model       string
+	system      string
+	maxTokens   int64
+	temperature float64
+}
+
+func (f *modelFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.model, "model", defaultModel, "model to use")
+	fs.StringVar(&f.system, "system", "", "system prompt")
+	fs.Int64Var(&f.maxTokens, "max-tokens", 0, "maximum number of completion tokens (default: the participant's limit)")
+	fs.Float64Var(&f.temperature, "temperature", -1, "sampling temperature (default: the model's)")
+}
+
+// params returns the request for messages, preceded by the system prompt.
+func (f *modelFlags) params(messages []openai.ChatCompletionMessageParamUnion) openai.ChatCompletionNewParams {
+	params := openai.ChatCompletionNewParams{Model: f.model}
+	if f.system != "" {
+		params.Messages = append(params.Messages, openai.SystemMessage(f.system))
+	}
+	params.Messages = append(params.Messages, messages...)
+	if f.maxTokens > 0 {
+		params.MaxCompletionTokens = openai.Int(f.maxTokens)
+	}
+	if f.temperature >= 0 {
+		params.Temperature = openai.Float(f.temperature)
+	}
+	return params
+}
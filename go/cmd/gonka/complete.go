@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/openai/openai-go"
+)
+
+// runComplete completes a single prompt, given as arguments or read from stdin,
+// and prints the reply's text or the whole response as JSON.
+func runComplete(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("complete", flag.ContinueOnError)
+	var client clientFlags
+	var model modelFlags
+	client.register(fs)
+	model.register(fs)
+	asJSON := fs.Bool("json", false, "print the response as JSON")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: gonka complete [flags] [prompt]\n\nWithout a prompt argument the prompt is read from stdin.\n\nflags:")
+		fs.PrintDefaults()
+	}
+	if err := parseFlags(fs, args, stderr); err != nil {
+		return err
+	}
+
+	prompt := strings.Join(fs.Args(), " ")
+	if prompt == "" {
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return fmt.Errorf("reading prompt: %w", err)
+		}
+		prompt = strings.TrimSpace(string(data))
+	}
+	if strings.TrimSpace(prompt) == "" {
+		return fmt.Errorf("empty prompt")
+	}
+
+	g, err := client.newClient(stderr)
+	if err != nil {
+		return err
+	}
+	completion, err := g.Chat.Completions.New(ctx, model.params([]openai.ChatCompletionMessageParamUnion{openai.UserMessage(prompt)}))
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		_, err = fmt.Fprintln(stdout, completion.RawJSON())
+		return err
+	}
+	if len(completion.Choices) == 0 {
+		return fmt.Errorf("the response has no choices")
+	}
+	_, err = fmt.Fprintln(stdout, completion.Choices[0].Message.Content)
+	return err
+}
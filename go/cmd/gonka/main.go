@@ -0,0 +1,88 @@
+// Command gonka talks to the Gonka network from the command line.
+//
+//	gonka chat [flags]               interactive chat with streamed replies
+//	gonka complete [flags] [prompt]  one-shot completion of a prompt or stdin
+//	gonka participants [flags]       participants of an epoch and their delegates
+//
+// The client is configured by the GONKA_* environment variables, which are also
+// read from a .env file in the working directory, or by flags.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/joho/godotenv"
+)
+
+const usage = `usage: gonka <command> [flags]
+
+commands:
+  chat          interactive chat with streamed replies
+  complete      one-shot completion of a prompt read from the arguments or stdin
+  participants  participants of an epoch with their models, weights and delegates
+
+Run 'gonka <command> -h' for the flags of a command.
+`
+
+func main() {
+	_ = godotenv.Load()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	code := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+// run runs the command in args and returns the exit code.
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	var cmd func(context.Context, []string, io.Reader, io.Writer, io.Writer) error
+	switch args[0] {
+	case "chat":
+		cmd = runChat
+	case "complete":
+		cmd = runComplete
+	case "participants":
+		cmd = runParticipants
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+		return 0
+	default:
+		fmt.Fprintf(stderr, "gonka: unknown command %q\n\n%s", args[0], usage)
+		return 2
+	}
+	err := cmd(ctx, args[1:], stdin, stdout, stderr)
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.Is(err, errUsage):
+		return 2
+	}
+	fmt.Fprintf(stderr, "gonka %s: %v\n", args[0], err)
+	return 1
+}
+
+// errUsage is returned for invalid flags, which the flag set has reported already.
+var errUsage = errors.New("invalid usage")
+
+// parseFlags parses args into fs, writing errors and help to stderr.
+func parseFlags(fs *flag.FlagSet, args []string, stderr io.Writer) error {
+	fs.SetOutput(stderr)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errUsage
+	}
+	return nil
+}
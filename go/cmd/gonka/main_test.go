@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testPrivateKey      = "10af8dc1f63fb90cfa39943a5afbf262cd84f24919e7d05653e3b03313e685ce"
+	testTransferAddress = "gonka1l3e9pgs3mmwuwrh95fecme0s0qtn2880el49wm"
+)
+
+type chatRequest struct {
+	Model    string `json:"model"`
+	Stream   bool   `json:"stream"`
+	Messages []struct {
+		Role    string `json:"role"`
+		Content string `json:"content"`
+	} `json:"messages"`
+}
+
+// newParticipant serves chat completions that reply with the last message and
+// " back", streamed if requested, and records the requests.
+func newParticipant(t *testing.T, requests *[]chatRequest) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req chatRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		*requests = append(*requests, req)
+		if r.Header.Get("Authorization") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		reply := req.Messages[len(req.Messages)-1].Content + " back"
+		if !req.Stream {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]any{
+				"id": "chatcmpl-1", "object": "chat.completion", "model": req.Model,
+				"choices": []any{map[string]any{"index": 0, "finish_reason": "stop",
+					"message": map[string]any{"role": "assistant", "content": reply}}},
+			})
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, part := range strings.SplitAfter(reply, " ") {
+			chunk, _ := json.Marshal(map[string]any{
+				"id": "chatcmpl-1", "object": "chat.completion.chunk", "model": req.Model,
+				"choices": []any{map[string]any{"index": 0, "delta": map[string]any{"content": part}}},
+			})
+			w.Write([]byte("data: " + string(chunk) + "\n\n"))
+		}
+		w.Write([]byte("data: [DONE]\n\n"))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func runCommand(t *testing.T, stdin string, args ...string) (code int, stdout, stderr string) {
+	var out, errOut bytes.Buffer
+	code = run(context.Background(), args, strings.NewReader(stdin), &out, &errOut)
+	return code, out.String(), errOut.String()
+}
+
+// keyFile writes the test private key to a file for -private-key-file.
+func keyFile(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "key")
+	require.NoError(t, os.WriteFile(path, []byte(testPrivateKey+"\n"), 0o600))
+	return path
+}
+
+func clientArgs(t *testing.T, srv *httptest.Server) []string {
+	return []string{"-private-key-file", keyFile(t), "-endpoints", srv.URL + "/v1;" + testTransferAddress}
+}
+
+func TestComplete(t *testing.T) {
+	var requests []chatRequest
+	srv := newParticipant(t, &requests)
+
+	code, stdout, stderr := runCommand(t, "hello\n", append([]string{"complete", "-model", "m", "-system", "be brief"}, clientArgs(t, srv)...)...)
+	require.Equal(t, 0, code, stderr)
+	assert.Equal(t, "hello back\n", stdout)
+	require.Len(t, requests, 1)
+	assert.Equal(t, "m", requests[0].Model)
+	assert.Equal(t, "system", requests[0].Messages[0].Role)
+
+	code, stdout, stderr = runCommand(t, "", append(append([]string{"complete", "-json"}, clientArgs(t, srv)...), "hi", "there")...)
+	require.Equal(t, 0, code, stderr)
+	var resp struct {
+		Choices []struct {
+			Message struct{ Content string }
+		}
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &resp))
+	assert.Equal(t, "hi there back", resp.Choices[0].Message.Content)
+}
+
+func TestChat(t *testing.T) {
+	var requests []chatRequest
+	srv := newParticipant(t, &requests)
+
+	input := "hello\n\n/history\nagain\n/reset\nfresh\n/exit\n"
+	code, stdout, stderr := runCommand(t, input, append([]string{"chat"}, clientArgs(t, srv)...)...)
+	require.Equal(t, 0, code, stderr)
+	assert.Contains(t, stdout, "hello back\n")
+	assert.Contains(t, stdout, "user: hello\nassistant: hello back\n")
+	require.Len(t, requests, 3)
+	assert.True(t, requests[0].Stream)
+	assert.Len(t, requests[1].Messages, 3, "history is sent along")
+	assert.Len(t, requests[2].Messages, 1, "history is reset")
+}
+
+func TestUsage(t *testing.T) {
+	code, _, stderr := runCommand(t, "")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "usage: gonka")
+
+	code, _, stderr = runCommand(t, "", "nope")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, `unknown command "nope"`)
+
+	code, _, _ = runCommand(t, "", "complete", "-bogus")
+	assert.Equal(t, 2, code)
+
+	code, _, stderr = runCommand(t, "", "complete", "-private-key-file", keyFile(t), "-endpoints", "nonsense", "hi")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "--endpoints")
+
+	code, _, stderr = runCommand(t, "", "complete", "-private-key-file", filepath.Join(t.TempDir(), "missing"), "hi")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "--private-key-file")
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	gonkaopenai "github.com/gonka-ai/gonka-openai/go"
+)
+
+const (
+	// identityTimeout bounds the lookup of a participant's delegates.
+	identityTimeout = 10 * time.Second
+	// identityLookups is how many participants are looked up at once.
+	identityLookups = 8
+)
+
+// participantsReport is what the participants command prints.
+type participantsReport struct {
+	SourceURL            string `json:"source_url,omitempty"`
+	Snapshot             string `json:"snapshot,omitempty"`
+	EpochID              uint64 `json:"epoch_id"`
+	BlockHeight          int64  `json:"block_height"`
+	PocStartBlockHeight  int64  `json:"poc_start_block_height"`
+	EffectiveBlockHeight int64  `json:"effective_block_height"`
+	CreatedAtBlockHeight int64  `json:"created_at_block_height"`
+	Verified             bool   `json:"verified"`
+	// AllowedAddresses is the proof status of the allowed transfer addresses,
+	// or "unknown" if they were not fetched.
+	AllowedAddresses string              `json:"allowed_addresses"`
+	Participants     []participantReport `json:"participants"`
+}
+
+type participantReport struct {
+	Address      string   `json:"address"`
+	InferenceURL string   `json:"inference_url"`
+	Models       []string `json:"models"`
+	Weight       int64    `json:"weight"`
+	Excluded     bool     `json:"excluded"`
+	// Allowed is nil if the allowed transfer addresses are unknown.
+	Allowed   *bool            `json:"allowed"`
+	Delegates []delegateReport `json:"delegates,omitempty"`
+	// IdentityError explains why the delegates could not be looked up.
+	IdentityError string `json:"identity_error,omitempty"`
+}
+
+type delegateReport struct {
+	URL     string `json:"url"`
+	Address string `json:"address"`
+}
+
+// runParticipants prints the participants of an epoch, fetched from a node or
+// read from a snapshot saved earlier.
+func runParticipants(ctx context.Context, args []string, _ io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("participants", flag.ContinueOnError)
+	sourceURL := fs.String("source-url", "", "node to fetch participants from (default $"+gonkaopenai.EnvSourceUrl+")")
+	networkName := fs.String("network", "", "network name or chain id (default $"+gonkaopenai.EnvNetwork+")")
+	epoch := fs.String("epoch", "current", "epoch to list the participants of")
+	verify := fs.Bool("verify", false, "verify the participants and allowed transfer addresses against proofs, and require delegates to be signed")
+	asJSON := fs.Bool("json", false, "print the participants as JSON")
+	save := fs.String("save", "", "save the payload as received to `file`, for offline use with -snapshot")
+	snapshot := fs.String("snapshot", "", "read the participants from a `file` saved with -save instead of fetching them")
+	delegates := fs.Bool("delegates", true, "look up the delegate transfer agents of each participant")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: gonka participants [flags]\n\nflags:")
+		fs.PrintDefaults()
+	}
+	if err := parseFlags(fs, args, stderr); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments %q", fs.Args())
+	}
+
+	network, err := resolveNetwork(*networkName)
+	if err != nil {
+		return err
+	}
+	network.VerifyProof = network.VerifyProof || *verify
+
+	var participants *gonkaopenai.Participants
+	report := participantsReport{AllowedAddresses: "unknown"}
+	if *snapshot != "" {
+		raw, err := os.ReadFile(*snapshot)
+		if err != nil {
+			return err
+		}
+		if participants, err = gonkaopenai.ParseParticipants(raw, network); err != nil {
+			return fmt.Errorf("snapshot %s: %w", *snapshot, err)
+		}
+		report.Snapshot = *snapshot
+	} else {
+		source := *sourceURL
+		if source == "" {
+			source = os.Getenv(gonkaopenai.EnvSourceUrl)
+		}
+		if source == "" {
+			return fmt.Errorf("no source URL: set -source-url or %s", gonkaopenai.EnvSourceUrl)
+		}
+		if participants, err = gonkaopenai.FetchParticipants(ctx, source, *epoch, network); err != nil {
+			return err
+		}
+		report.SourceURL = source
+	}
+	if *save != "" {
+		if err := os.WriteFile(*save, participants.Raw, 0o644); err != nil {
+			return err
+		}
+	}
+
+	report.EpochID = participants.EpochID
+	report.BlockHeight = participants.BlockHeight
+	report.PocStartBlockHeight = participants.PocStartBlockHeight
+	report.EffectiveBlockHeight = participants.EffectiveBlockHeight
+	report.CreatedAtBlockHeight = participants.CreatedAtBlockHeight
+	report.Verified = participants.Verified
+	for _, p := range participants.Participants {
+		report.Participants = append(report.Participants, participantReport{
+			Address:      p.Index,
+			InferenceURL: p.InferenceUrl,
+			Models:       p.Models,
+			Weight:       p.Weight,
+			Excluded:     participants.Excluded[p.Index],
+		})
+	}
+
+	// Allowed transfer addresses and identities are only available online
+	if report.SourceURL != "" {
+		allowed := gonkaopenai.FetchVerifiedAllowedTransferAddresses(ctx, report.SourceURL, network)
+		report.AllowedAddresses = allowed.Status.String()
+		if allowed.Status != gonkaopenai.ProofFetchFailed {
+			for i := range report.Participants {
+				ok := allowed.Addresses[report.Participants[i].Address]
+				report.Participants[i].Allowed = &ok
+			}
+		} else {
+			fmt.Fprintf(stderr, "warning: allowed transfer addresses: %v\n", allowed.Err)
+		}
+		if *delegates {
+			lookupDelegates(ctx, report.Participants, *verify)
+		}
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	return printParticipants(stdout, report)
+}
+
+// resolveNetwork returns the network named name, or by GONKA_NETWORK, or the default network.
+func resolveNetwork(name string) (gonkaopenai.Network, error) {
+	if name == "" {
+		name = os.Getenv(gonkaopenai.EnvNetwork)
+	}
+	if name == "" {
+		return gonkaopenai.DefaultNetwork, nil
+	}
+	network, ok := gonkaopenai.NetworkByName(name)
+	if !ok {
+		return gonkaopenai.Network{}, fmt.Errorf("unknown network %q", name)
+	}
+	return network, nil
+}
+
+// lookupDelegates fills in the delegate transfer agents of participants from
+// their identities, requiring them to be signed if verify is set.
+func lookupDelegates(ctx context.Context, participants []participantReport, verify bool) {
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, identityLookups)
+	for i := range participants {
+		p := &participants[i]
+		if p.InferenceURL == "" {
+			continue
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			ctx, cancel := context.WithTimeout(ctx, identityTimeout)
+			defer cancel()
+			var endpoints []gonkaopenai.Endpoint
+			var err error
+			if verify {
+				endpoints, err = gonkaopenai.FetchVerifiedNodeIdentity(ctx, p.InferenceURL, p.Address)
+			} else {
+				endpoints, err = gonkaopenai.FetchNodeIdentity(ctx, p.InferenceURL)
+			}
+			if err != nil {
+				p.IdentityError = err.Error()
+				return
+			}
+			for _, ep := range endpoints {
+				p.Delegates = append(p.Delegates, delegateReport{URL: ep.URL, Address: ep.Address})
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func printParticipants(w io.Writer, report participantsReport) error {
+	source := report.SourceURL
+	if report.Snapshot != "" {
+		source = "snapshot " + report.Snapshot
+	}
+	fmt.Fprintf(w, "source:            %s\n", source)
+	fmt.Fprintf(w, "epoch:             %d\n", report.EpochID)
+	fmt.Fprintf(w, "block height:      %d (poc start %d, effective %d, created at %d)\n",
+		report.BlockHeight, report.PocStartBlockHeight, report.EffectiveBlockHeight, report.CreatedAtBlockHeight)
+	fmt.Fprintf(w, "verified:          %s\n", yesNo(report.Verified))
+	fmt.Fprintf(w, "allowed addresses: %s\n\n", report.AllowedAddresses)
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ADDRESS\tINFERENCE URL\tWEIGHT\tMODELS\tEXCLUDED\tALLOWED\tDELEGATES")
+	for _, p := range report.Participants {
+		allowed := "?"
+		if p.Allowed != nil {
+			allowed = yesNo(*p.Allowed)
+		}
+		var delegates []string
+		for _, d := range p.Delegates {
+			delegates = append(delegates, d.URL+" ("+d.Address+")")
+		}
+		if p.IdentityError != "" {
+			delegates = append(delegates, "error: "+p.IdentityError)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", p.Address, p.InferenceURL, strconv.FormatInt(p.Weight, 10),
+			orDash(strings.Join(p.Models, ",")), yesNo(p.Excluded), allowed, orDash(strings.Join(delegates, ", ")))
+	}
+	return tw.Flush()
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
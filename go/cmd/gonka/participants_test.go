@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const excludedAddress = "gonka1excluded"
+
+// newNode serves the participants of epoch 7 and the identity of its first
+// participant, which delegates to a transfer agent. Anything else is not found,
+// so the allowed transfer addresses cannot be fetched.
+func newNode(t *testing.T) *httptest.Server {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/epochs/current/participants":
+			w.Write([]byte(`{"block":{"header":{"height":"1200"}},"active_participants":{"epoch_id":7,` +
+				`"poc_start_block_height":1000,"effective_block_height":1010,"created_at_block_height":1005,"participants":[` +
+				`{"index":"` + testTransferAddress + `","inference_url":"` + srv.URL + `","weight":30,"models":["m1","m2"]},` +
+				`{"index":"` + excludedAddress + `","weight":5}]},` +
+				`"excluded_participants":[{"address":"` + excludedAddress + `"}]}`))
+		case "/v1/identity":
+			w.Write([]byte(`{"data":{"delegate_ta":{"https://ta.test":"` + testTransferAddress + `"}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestParticipants(t *testing.T) {
+	srv := newNode(t)
+	snapshot := filepath.Join(t.TempDir(), "participants.json")
+
+	code, stdout, stderr := runCommand(t, "", "participants", "-source-url", srv.URL, "-save", snapshot)
+	require.Equal(t, 0, code, stderr)
+	assert.Contains(t, stderr, "warning: allowed transfer addresses")
+	assert.Contains(t, stdout, "epoch:             7\n")
+	assert.Contains(t, stdout, "block height:      1200 (poc start 1000, effective 1010, created at 1005)\n")
+	assert.Contains(t, stdout, "allowed addresses: fetch-failed\n")
+	assert.Regexp(t, testTransferAddress+` +`+srv.URL+` +30 +m1,m2 +no +\? +https://ta.test/v1 \(`+testTransferAddress+`\)`, stdout)
+	assert.Regexp(t, excludedAddress+` +5 +- +yes +\? +-`, stdout)
+
+	raw, err := os.ReadFile(snapshot)
+	require.NoError(t, err)
+	assert.Contains(t, string(raw), `"epoch_id":7`)
+
+	// The snapshot is read without any network calls
+	srv.Close()
+	code, stdout, stderr = runCommand(t, "", "participants", "-snapshot", snapshot, "-json")
+	require.Equal(t, 0, code, stderr)
+	var report participantsReport
+	require.NoError(t, json.Unmarshal([]byte(stdout), &report))
+	assert.Equal(t, snapshot, report.Snapshot)
+	assert.Equal(t, uint64(7), report.EpochID)
+	assert.Equal(t, int64(1200), report.BlockHeight)
+	assert.Equal(t, "unknown", report.AllowedAddresses)
+	require.Len(t, report.Participants, 2)
+	assert.Equal(t, participantReport{
+		Address: testTransferAddress, InferenceURL: srv.URL, Models: []string{"m1", "m2"}, Weight: 30,
+	}, report.Participants[0])
+	assert.True(t, report.Participants[1].Excluded)
+	assert.Nil(t, report.Participants[1].Allowed)
+}
+
+func TestParticipantsErrors(t *testing.T) {
+	t.Setenv("GONKA_SOURCE_URL", "")
+	t.Setenv("GONKA_NETWORK", "")
+	code, _, stderr := runCommand(t, "", "participants")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "no source URL")
+
+	code, _, stderr = runCommand(t, "", "participants", "-network", "nope")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, `unknown network "nope"`)
+
+	code, _, stderr = runCommand(t, "", "participants", "-snapshot", filepath.Join(t.TempDir(), "missing.json"))
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "missing.json")
+}
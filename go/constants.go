@@ -6,7 +6,11 @@ const (
 	EnvAddress    = "GONKA_ADDRESS"
 	EnvSourceUrl  = "GONKA_SOURCE_URL"
 	EnvEndpoints  = "GONKA_ENDPOINTS"
+	EnvNetwork    = "GONKA_NETWORK"
+	EnvDebug      = "GONKA_DEBUG"
 )
 
 // Gonka chain ID used for address derivation
+//
+// Deprecated: select a Network profile via Options.Network or GONKA_NETWORK instead.
 const GonkaChainID = "gonka-testnet-3"
@@ -0,0 +1,233 @@
+package gonkaopenai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gonka-ai/gonka-openai/go/chainclient"
+	"github.com/openai/openai-go"
+)
+
+// ErrCostCeilingExceeded is returned for requests refused because their estimated
+// cost exceeds Options.MaxRequestCost.
+var ErrCostCeilingExceeded = errors.New("estimated cost exceeds ceiling")
+
+// ErrCostOverflow is returned when an estimated cost does not fit in a uint64.
+var ErrCostOverflow = errors.New("estimated cost overflows")
+
+// DefaultMaxCompletionTokens is the completion budget assumed for requests that set
+// neither max_completion_tokens nor max_tokens. It matches the nodes' default.
+const DefaultMaxCompletionTokens = 5000
+
+// DefaultPriceCacheTTL is how long a model's per-token price is reused before it
+// is fetched from the chain again.
+const DefaultPriceCacheTTL = time.Minute
+
+// messageTokenOverhead approximates the tokens added per message by the chat template.
+const messageTokenOverhead = 4
+
+// Tokenizer counts the tokens of text as model would tokenize it.
+type Tokenizer func(model, text string) (int, error)
+
+// ApproximateTokenizer estimates one token per four bytes of text. It is the
+// default Tokenizer; plug in the model's real tokenizer for exact counts.
+func ApproximateTokenizer(model, text string) (int, error) {
+	return (len(text) + 3) / 4, nil
+}
+
+// CostEstimate is the expected cost of a chat completion request. Costs are in
+// the chain's base denomination.
+type CostEstimate struct {
+	Model        string
+	PromptTokens int
+	// MaxCompletionTokens is the request's completion limit, or DefaultMaxCompletionTokens.
+	MaxCompletionTokens int
+	PricePerToken       uint64
+	// PromptCost is the cost of the prompt alone, the least the request can cost.
+	PromptCost uint64
+	// MaxCost is the cost if the whole completion budget is used. It is what the
+	// cost ceiling is checked against.
+	MaxCost uint64
+}
+
+// EstimateCost estimates the cost of a chat completion request from the model's
+// on-chain per-token price and a local count of the prompt tokens.
+func (g *GonkaOpenAI) EstimateCost(ctx context.Context, params openai.ChatCompletionNewParams) (*CostEstimate, error) {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	return g.estimateCost(ctx, body)
+}
+
+// chatRequest is the part of a chat completion request needed to estimate its cost.
+type chatRequest struct {
+	Model    string `json:"model"`
+	Messages []struct {
+		Content json.RawMessage `json:"content"`
+	} `json:"messages"`
+	MaxCompletionTokens int `json:"max_completion_tokens"`
+	MaxTokens           int `json:"max_tokens"`
+}
+
+func (g *GonkaOpenAI) estimateCost(ctx context.Context, body []byte) (*CostEstimate, error) {
+	var req chatRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, fmt.Errorf("failed to decode chat completion request: %w", err)
+	}
+	if req.Model == "" {
+		return nil, fmt.Errorf("chat completion request has no model")
+	}
+
+	tokenizer := g.tokenizer
+	if tokenizer == nil {
+		tokenizer = ApproximateTokenizer
+	}
+	promptTokens := 0
+	for _, m := range req.Messages {
+		n, err := tokenizer(req.Model, messageText(m.Content))
+		if err != nil {
+			return nil, fmt.Errorf("failed to count tokens: %w", err)
+		}
+		if n < 0 {
+			return nil, fmt.Errorf("failed to count tokens: tokenizer returned %d", n)
+		}
+		promptTokens += n + messageTokenOverhead
+	}
+
+	maxTokens := req.MaxCompletionTokens
+	if maxTokens == 0 {
+		maxTokens = req.MaxTokens
+	}
+	if maxTokens == 0 {
+		maxTokens = DefaultMaxCompletionTokens
+	}
+	if maxTokens < 0 {
+		return nil, fmt.Errorf("chat completion request has negative max tokens %d", maxTokens)
+	}
+
+	price, err := g.prices.get(ctx, req.Model)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch price of %s: %w", req.Model, err)
+	}
+	promptCost, err := tokenCost(uint64(promptTokens), price)
+	if err != nil {
+		return nil, err
+	}
+	maxCost, err := tokenCost(uint64(promptTokens)+uint64(maxTokens), price)
+	if err != nil {
+		return nil, err
+	}
+	return &CostEstimate{
+		Model:               req.Model,
+		PromptTokens:        promptTokens,
+		MaxCompletionTokens: maxTokens,
+		PricePerToken:       price,
+		PromptCost:          promptCost,
+		MaxCost:             maxCost,
+	}, nil
+}
+
+// tokenCost returns tokens * price, or ErrCostOverflow if it does not fit in a uint64.
+func tokenCost(tokens, price uint64) (uint64, error) {
+	hi, lo := bits.Mul64(tokens, price)
+	if hi != 0 {
+		return 0, fmt.Errorf("%w: %d tokens at %d per token", ErrCostOverflow, tokens, price)
+	}
+	return lo, nil
+}
+
+// messageText returns the text of a message's content, which is either a string
+// or an array of content parts. Non-text parts are ignored.
+func messageText(content json.RawMessage) string {
+	var s string
+	if json.Unmarshal(content, &s) == nil {
+		return s
+	}
+	var parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if json.Unmarshal(content, &parts) != nil {
+		return ""
+	}
+	var b strings.Builder
+	for _, p := range parts {
+		if p.Type == "text" {
+			b.WriteString(p.Text)
+		}
+	}
+	return b.String()
+}
+
+// costCheck is registered with the signing transport when Options.MaxRequestCost
+// is set. Chat completion requests whose cost cannot be estimated are refused too,
+// since the ceiling could not be enforced for them.
+func (g *GonkaOpenAI) costCheck(req *http.Request) error {
+	if req.Method != http.MethodPost || !strings.HasSuffix(req.URL.Path, "/chat/completions") || req.Body == nil {
+		return nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read request body: %w", err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	estimate, err := g.estimateCost(req.Context(), body)
+	if err != nil {
+		return fmt.Errorf("%w: cost could not be estimated: %v", ErrCostCeilingExceeded, err)
+	}
+	if estimate.MaxCost > g.maxCost {
+		return fmt.Errorf("%w: %s request may cost %d, ceiling is %d", ErrCostCeilingExceeded, estimate.Model, estimate.MaxCost, g.maxCost)
+	}
+	return nil
+}
+
+// priceCache caches per-token model prices fetched from the chain.
+type priceCache struct {
+	chain *chainclient.Client
+	ttl   time.Duration
+
+	mu     sync.Mutex
+	prices map[string]cachedPrice
+}
+
+type cachedPrice struct {
+	price   uint64
+	fetched time.Time
+}
+
+func newPriceCache(chain *chainclient.Client, ttl time.Duration) *priceCache {
+	if ttl <= 0 {
+		ttl = DefaultPriceCacheTTL
+	}
+	return &priceCache{chain: chain, ttl: ttl, prices: make(map[string]cachedPrice)}
+}
+
+func (c *priceCache) get(ctx context.Context, model string) (uint64, error) {
+	c.mu.Lock()
+	cached, ok := c.prices[model]
+	c.mu.Unlock()
+	if ok && time.Since(cached.fetched) < c.ttl {
+		return cached.price, nil
+	}
+
+	price, err := c.chain.ModelPerTokenPrice(ctx, model)
+	if err != nil {
+		return 0, err
+	}
+	c.mu.Lock()
+	c.prices[model] = cachedPrice{price: price, fetched: time.Now()}
+	c.mu.Unlock()
+	return price, nil
+}
@@ -0,0 +1,89 @@
+package gonkaopenai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/openai/openai-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPricingServer(t *testing.T, sent *int) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/chain-api/productscience/inference/inference/get_model_per_token_price/"):
+			w.Write([]byte(`{"price":"10","found":true}`))
+		default:
+			*sent++
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"id":"chatcmpl-1","object":"chat.completion","model":"m","choices":[]}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestEstimateCost(t *testing.T) {
+	var sent int
+	srv := newPricingServer(t, &sent)
+	g, err := NewGonkaOpenAI(Options{
+		GonkaPrivateKey: testPrivateKey,
+		Endpoints:       []Endpoint{{URL: srv.URL + "/v1", Address: testTransferAddress}},
+		Tokenizer:       func(model, text string) (int, error) { return len(strings.Fields(text)), nil },
+	})
+	require.NoError(t, err)
+
+	estimate, err := g.EstimateCost(context.Background(), openai.ChatCompletionNewParams{
+		Model: "m",
+		Messages: []openai.ChatCompletionMessageParamUnion{
+			openai.SystemMessage("be brief"),
+			openai.UserMessage("hello there world"),
+		},
+		MaxCompletionTokens: openai.Int(100),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2+3+2*messageTokenOverhead, estimate.PromptTokens)
+	assert.Equal(t, 100, estimate.MaxCompletionTokens)
+	assert.Equal(t, uint64(10), estimate.PricePerToken)
+	assert.Equal(t, uint64(130), estimate.PromptCost)
+	assert.Equal(t, uint64(1130), estimate.MaxCost)
+
+	// A cost that does not fit in a uint64 is an error rather than wrapping around
+	_, err = g.EstimateCost(context.Background(), openai.ChatCompletionNewParams{
+		Model:               "m",
+		Messages:            []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hi")},
+		MaxCompletionTokens: openai.Int(1 << 62),
+	})
+	assert.ErrorIs(t, err, ErrCostOverflow)
+}
+
+func TestMaxRequestCost(t *testing.T) {
+	var sent int
+	srv := newPricingServer(t, &sent)
+	g, err := NewGonkaOpenAI(Options{
+		GonkaPrivateKey: testPrivateKey,
+		Endpoints:       []Endpoint{{URL: srv.URL + "/v1", Address: testTransferAddress}},
+		MaxRequestCost:  5000,
+	})
+	require.NoError(t, err)
+
+	params := openai.ChatCompletionNewParams{
+		Model:               "m",
+		Messages:            []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hi")},
+		MaxCompletionTokens: openai.Int(100),
+	}
+	_, err = g.Chat.Completions.New(context.Background(), params)
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+
+	// Without a limit the default completion budget exceeds the ceiling
+	params.MaxCompletionTokens = openai.ChatCompletionNewParams{}.MaxCompletionTokens
+	_, err = g.Chat.Completions.New(context.Background(), params)
+	assert.ErrorIs(t, err, ErrCostCeilingExceeded)
+	assert.Equal(t, 1, sent)
+}
@@ -0,0 +1,138 @@
+package gonkaopenai
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxDebugBody bounds how much of a request body is dumped in debug mode.
+// Response bodies are dumped up to maxObservedHead.
+const maxDebugBody = 64 << 10
+
+// debugAuthorizationPrefix is how much of the Authorization header is dumped.
+const debugAuthorizationPrefix = 12
+
+// debugRedactedHeaders are dumped without their values.
+var debugRedactedHeaders = map[string]bool{
+	"Cookie":              true,
+	"Set-Cookie":          true,
+	"Proxy-Authorization": true,
+}
+
+// debugEnabled reports whether wire dumps are enabled by opt or GONKA_DEBUG.
+func debugEnabled(opt bool) bool {
+	if opt {
+		return true
+	}
+	v, _ := strconv.ParseBool(os.Getenv(EnvDebug))
+	return v
+}
+
+// debugDumper writes signed requests and their responses to w. A nil
+// *debugDumper dumps nothing.
+type debugDumper struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// newDebugDumper returns a dumper writing to w, or os.Stderr if w is nil, if
+// dumping is enabled.
+func newDebugDumper(enabled bool, w io.Writer) *debugDumper {
+	if !debugEnabled(enabled) {
+		return nil
+	}
+	if w == nil {
+		w = os.Stderr
+	}
+	return &debugDumper{w: w}
+}
+
+func (d *debugDumper) write(b *bytes.Buffer) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	_, _ = d.w.Write(b.Bytes())
+}
+
+// request dumps a signed request with the components its signature covers.
+func (d *debugDumper) request(req *http.Request, components SignatureComponents, requester string, attempt int) {
+	if d == nil {
+		return
+	}
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "--> %s %s (attempt %d)\n", req.Method, req.URL, attempt)
+	sum := sha256.Sum256([]byte(components.Payload))
+	fmt.Fprintf(&b, "    signed body sha256: %s\n", hex.EncodeToString(sum[:]))
+	fmt.Fprintf(&b, "    signed timestamp: %d\n", components.Timestamp)
+	fmt.Fprintf(&b, "    signed transfer address: %s\n", components.TransferAddress)
+	fmt.Fprintf(&b, "    requester address: %s\n", requester)
+	dumpHeader(&b, req.Header)
+	dumpBody(&b, []byte(components.Payload), len(components.Payload) > maxDebugBody)
+	d.write(&b)
+}
+
+// response dumps the response to req, given the start of its body.
+func (d *debugDumper) response(req *http.Request, resp *http.Response, head []byte, elapsed time.Duration) {
+	if d == nil {
+		return
+	}
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "<-- %s %s %s (%s)\n", resp.Status, req.Method, req.URL, elapsed.Round(time.Millisecond))
+	dumpHeader(&b, resp.Header)
+	dumpBody(&b, head, len(head) >= maxObservedHead)
+	d.write(&b)
+}
+
+// failure dumps an attempt that received no response.
+func (d *debugDumper) failure(req *http.Request, err error, elapsed time.Duration) {
+	if d == nil {
+		return
+	}
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "<-- error %s %s (%s): %v\n\n", req.Method, req.URL, elapsed.Round(time.Millisecond), err)
+	d.write(&b)
+}
+
+func dumpHeader(b *bytes.Buffer, header http.Header) {
+	keys := make([]string, 0, len(header))
+	for k := range header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range header[k] {
+			switch {
+			case debugRedactedHeaders[k]:
+				v = redactedValue
+			case k == "Authorization" && len(v) > debugAuthorizationPrefix:
+				v = fmt.Sprintf("%s... (%d bytes)", v[:debugAuthorizationPrefix], len(v))
+			}
+			fmt.Fprintf(b, "    %s: %s\n", k, v)
+		}
+	}
+}
+
+func dumpBody(b *bytes.Buffer, body []byte, truncated bool) {
+	b.WriteString("\n")
+	if len(body) > maxDebugBody {
+		body = body[:maxDebugBody]
+	}
+	if len(body) > 0 {
+		b.WriteString("    ")
+		b.WriteString(strings.ReplaceAll(strings.TrimRight(string(body), "\n"), "\n", "\n    "))
+		b.WriteString("\n")
+	}
+	if truncated {
+		b.WriteString("    [truncated]\n")
+	}
+	b.WriteString("\n")
+}
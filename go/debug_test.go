@@ -0,0 +1,70 @@
+package gonkaopenai
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openai/openai-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebugDump(t *testing.T) {
+	var signature string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":{"message":"invalid signature"}}`))
+	}))
+	defer srv.Close()
+
+	var out bytes.Buffer
+	g, err := NewGonkaOpenAI(Options{
+		GonkaPrivateKey: testPrivateKey,
+		Endpoints:       []Endpoint{{URL: srv.URL + "/v1", Address: testTransferAddress}},
+		Debug:           true,
+		DebugWriter:     &out,
+	})
+	require.NoError(t, err)
+
+	_, err = g.Chat.Completions.New(context.Background(), openai.ChatCompletionNewParams{
+		Model:    "m",
+		Messages: []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hi")},
+	})
+	require.Error(t, err)
+
+	dump := out.String()
+	require.NotEmpty(t, signature)
+	assert.Contains(t, dump, "--> POST "+srv.URL+"/v1/chat/completions (attempt 0)")
+	assert.Contains(t, dump, "signed transfer address: "+testTransferAddress)
+	assert.Contains(t, dump, "Authorization: "+signature[:debugAuthorizationPrefix]+"...")
+	assert.NotContains(t, dump, signature)
+	assert.Contains(t, dump, "<-- 401 Unauthorized POST")
+	assert.Contains(t, dump, "invalid signature")
+	assert.Contains(t, dump, "Set-Cookie: "+redactedValue)
+	assert.NotContains(t, dump, "session=secret")
+}
+
+func TestDebugDumpSignedComponents(t *testing.T) {
+	var out bytes.Buffer
+	d := newDebugDumper(true, &out)
+	req := httptest.NewRequest(http.MethodPost, "http://participant.test/v1/chat/completions", nil)
+	d.request(req, SignatureComponents{Payload: `{"model":"m"}`, Timestamp: 123, TransferAddress: testTransferAddress}, "gonka1requester", 2)
+
+	sum := sha256.Sum256([]byte(`{"model":"m"}`))
+	assert.Contains(t, out.String(), "signed body sha256: "+hex.EncodeToString(sum[:]))
+	assert.Contains(t, out.String(), "signed timestamp: 123")
+	assert.Contains(t, out.String(), "(attempt 2)")
+
+	t.Setenv(EnvDebug, "")
+	assert.Nil(t, newDebugDumper(false, &out))
+	t.Setenv(EnvDebug, "1")
+	assert.NotNil(t, newDebugDumper(false, &out))
+}
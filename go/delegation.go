@@ -0,0 +1,89 @@
+package gonkaopenai
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/gonka-ai/gonka-openai/go/chainclient"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ErrDelegationUnverified is returned when a participant delegates to transfer
+// agents but the delegation cannot be verified. Such a delegation is refused
+// rather than followed.
+var ErrDelegationUnverified = errors.New("delegation could not be verified")
+
+// FetchVerifiedNodeIdentity fetches the node identity like FetchNodeIdentity and,
+// if it delegates to transfer agents, checks that it was signed by the key of
+// participantAddress.
+//
+// The identity response carries a "signature" field next to "data": a base64
+// secp256k1 signature (r||s) over the SHA-256 of the exact bytes of "data", as
+// produced by GonkaSignature. This is the client's own convention; no node
+// implementation signs identities yet. An identity without delegate_ta needs
+// no signature.
+func FetchVerifiedNodeIdentity(ctx context.Context, nodeUrl, participantAddress string) (_ []Endpoint, err error) {
+	ctx, span := startSpan(ctx, "gonka.FetchNodeIdentity",
+		attribute.String("gonka.node_url", nodeUrl),
+		attribute.String("gonka.participant", participantAddress),
+	)
+	defer func() { endSpan(span, err) }()
+	identity, err := fetchNodeIdentity(ctx, nodeUrl)
+	if err != nil {
+		return nil, err
+	}
+	endpoints, err := identity.delegateEndpoints()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDelegationUnverified, err)
+	}
+	if len(endpoints) == 0 {
+		return nil, nil
+	}
+	if err := identity.verify(participantAddress); err != nil {
+		return nil, fmt.Errorf("%w: identity of %s: %v", ErrDelegationUnverified, participantAddress, err)
+	}
+	return endpoints, nil
+}
+
+// verify checks that the identity data was signed by the key behind address.
+func (n *nodeIdentity) verify(address string) error {
+	if n.Signature == "" {
+		return ErrMissingSignature
+	}
+	sig, err := base64.StdEncoding.DecodeString(n.Signature)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrMalformedSignature, err)
+	}
+	if len(sig) != 64 {
+		return fmt.Errorf("%w: expected 64 bytes, got %d", ErrMalformedSignature, len(sig))
+	}
+	prefix, _, err := DecodeAddress(address)
+	if err != nil {
+		return err
+	}
+	hash := sha256.Sum256(n.Data)
+	return recoverSigner(hash[:], sig, address, prefix)
+}
+
+// verifyDelegation checks delegate transfer agents against the chain: the
+// delegating participant must be registered with the URL its identity was
+// fetched from, and every delegate must be an allowed transfer address.
+func verifyDelegation(ctx context.Context, chain *chainclient.Client, participant Endpoint, delegates []Endpoint, allowed AllowedTransferAddresses) error {
+	record, err := chain.Participant(ctx, participant.Address)
+	if err != nil {
+		return fmt.Errorf("%w: failed to fetch participant %s: %v", ErrDelegationUnverified, participant.Address, err)
+	}
+	if ensureV1(record.InferenceUrl) != ensureV1(participant.URL) {
+		return fmt.Errorf("%w: participant %s is registered with %s, not %s",
+			ErrDelegationUnverified, participant.Address, record.InferenceUrl, participant.URL)
+	}
+	for _, d := range delegates {
+		if !allowed.Addresses[d.Address] {
+			return fmt.Errorf("%w: delegate %s (%s) is not an allowed transfer address", ErrDelegationUnverified, d.URL, d.Address)
+		}
+	}
+	return nil
+}
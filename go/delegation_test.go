@@ -0,0 +1,139 @@
+package gonkaopenai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gonka-ai/gonka-openai/go/chainclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testOtherPrivateKey = "5bf7d25ac2b4b2d1a8e3c7c7e1f4d3f2b9a8c6d5e4f3a2b1c0d9e8f7a6b5c4d3"
+
+func newIdentityServer(t *testing.T, data, signature string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/identity" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"data":` + data + `,"signature":"` + signature + `"}`))
+	}))
+}
+
+func TestFetchVerifiedNodeIdentity(t *testing.T) {
+	participant := mustAddress(t, testPrivateKey)
+	data := `{"delegate_ta":{"https://ta.example.com":"` + testTransferAddress + `"}}`
+	signature, err := GonkaSignature([]byte(data), testPrivateKey)
+	require.NoError(t, err)
+
+	srv := newIdentityServer(t, data, signature)
+	defer srv.Close()
+	endpoints, err := FetchVerifiedNodeIdentity(context.Background(), srv.URL+"/v1", participant)
+	require.NoError(t, err)
+	assert.Equal(t, []Endpoint{{URL: "https://ta.example.com/v1", Address: testTransferAddress}}, endpoints)
+
+	// A prefix containing the bech32 separator
+	prefixed, err := Network{Bech32Prefix: "test1net"}.Address(testPrivateKey)
+	require.NoError(t, err)
+	_, err = FetchVerifiedNodeIdentity(context.Background(), srv.URL+"/v1", prefixed)
+	require.NoError(t, err)
+
+	// Signed by a different key
+	other, err := GonkaSignature([]byte(data), testOtherPrivateKey)
+	require.NoError(t, err)
+	srv2 := newIdentityServer(t, data, other)
+	defer srv2.Close()
+	_, err = FetchVerifiedNodeIdentity(context.Background(), srv2.URL, participant)
+	assert.ErrorIs(t, err, ErrDelegationUnverified)
+
+	// Unsigned
+	srv3 := newIdentityServer(t, data, "")
+	defer srv3.Close()
+	_, err = FetchVerifiedNodeIdentity(context.Background(), srv3.URL, participant)
+	assert.ErrorIs(t, err, ErrDelegationUnverified)
+
+	// No delegation needs no signature
+	srv4 := newIdentityServer(t, `{"address":"`+participant+`"}`, "")
+	defer srv4.Close()
+	endpoints, err = FetchVerifiedNodeIdentity(context.Background(), srv4.URL, participant)
+	require.NoError(t, err)
+	assert.Empty(t, endpoints)
+}
+
+func TestVerifyDelegation(t *testing.T) {
+	participantAddr := mustAddress(t, testPrivateKey)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/participant/"+participantAddr) {
+			w.Write([]byte(`{"participant":{"index":"` + participantAddr + `","address":"` + participantAddr +
+				`","inference_url":"https://node.example.com"}}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+	chain := chainclient.New(srv.URL, chainclient.Options{})
+
+	participant := Endpoint{URL: "https://node.example.com/v1", Address: participantAddr}
+	delegates := []Endpoint{{URL: "https://ta.example.com/v1", Address: testTransferAddress}}
+	allowed := AllowedTransferAddresses{Addresses: map[string]bool{testTransferAddress: true}, Status: ProofVerified}
+
+	require.NoError(t, verifyDelegation(context.Background(), chain, participant, delegates, allowed))
+
+	// Delegate not on the allowed list
+	err := verifyDelegation(context.Background(), chain, participant, delegates, AllowedTransferAddresses{})
+	assert.ErrorIs(t, err, ErrDelegationUnverified)
+
+	// Identity fetched from a URL the participant is not registered with
+	moved := Endpoint{URL: "https://evil.example.com/v1", Address: participantAddr}
+	err = verifyDelegation(context.Background(), chain, moved, delegates, allowed)
+	assert.ErrorIs(t, err, ErrDelegationUnverified)
+
+	// Participant unknown to the chain
+	unknown := Endpoint{URL: participant.URL, Address: testTransferAddress}
+	err = verifyDelegation(context.Background(), chain, unknown, delegates, allowed)
+	assert.ErrorIs(t, err, ErrDelegationUnverified)
+}
+
+func TestDelegateOverride(t *testing.T) {
+	participant := mustAddress(t, testPrivateKey)
+	data := `{"delegate_ta":{"https://ta.example.com":"` + testTransferAddress + `"}}`
+	signature, err := GonkaSignature([]byte(data), testPrivateKey)
+	require.NoError(t, err)
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v1/epochs/current/participants":
+			w.Write([]byte(`{"active_participants":{"epoch_id":1,"participants":[{"index":"` + participant +
+				`","inference_url":"` + srv.URL + `"}]}}`))
+		case strings.HasSuffix(r.URL.Path, "/inference/params"):
+			w.Write([]byte(`{"params":{"transfer_agent_access_params":{"allowed_transfer_addresses":["` +
+				participant + `","` + testTransferAddress + `"]}}}`))
+		case strings.HasSuffix(r.URL.Path, "/participant/"+participant):
+			w.Write([]byte(`{"participant":{"index":"` + participant + `","inference_url":"` + srv.URL + `"}}`))
+		case r.URL.Path == "/v1/identity":
+			w.Write([]byte(`{"data":` + data + `,"signature":"` + signature + `"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	// A verified delegation is followed, signing for the participant
+	g, err := NewGonkaOpenAI(Options{GonkaPrivateKey: testPrivateKey, SourceUrl: srv.URL})
+	require.NoError(t, err)
+	assert.Equal(t, "https://ta.example.com/v1", g.baseURL)
+	assert.Equal(t, []Endpoint{{URL: "https://ta.example.com/v1", Address: participant}}, g.endpoints)
+
+	// An unsigned delegation is refused and the participant is kept
+	signature = ""
+	g, err = NewGonkaOpenAI(Options{GonkaPrivateKey: testPrivateKey, SourceUrl: srv.URL})
+	require.NoError(t, err)
+	assert.Equal(t, srv.URL+"/v1", g.baseURL)
+	assert.Equal(t, []Endpoint{{URL: srv.URL + "/v1", Address: participant}}, g.endpoints)
+}
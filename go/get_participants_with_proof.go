@@ -8,11 +8,11 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"os"
 
 	cryptotypes "github.com/cometbft/cometbft/proto/tendermint/crypto"
 	"github.com/cosmos/gogoproto/proto"
 	ics23 "github.com/cosmos/ics23/go"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 var ErrInvalidEpoch = errors.New("invalid epoch")
@@ -21,7 +21,143 @@ var ErrInvalidEpoch = errors.New("invalid epoch")
 // verifies the proof, and returns a list of Endpoints.
 // This function is independent of the GonkaOpenAI client.
 // Specify "current" as the epoch to fetch the current participants.
+// The network is taken from GONKA_NETWORK, or DefaultNetwork if unset.
 func GetParticipantsWithProof(ctx context.Context, baseURL string, epoch string) ([]Endpoint, error) {
+	network, err := resolveNetwork(Network{})
+	if err != nil {
+		return nil, err
+	}
+	participants, err := getParticipantsWithProof(ctx, baseURL, epoch, network)
+	if err != nil {
+		return nil, err
+	}
+	return participants.Endpoints(), nil
+}
+
+// FetchParticipants fetches the participants of an epoch like
+// GetParticipantsWithProof, but returns them in full, together with the payload
+// as received so that it can be saved and parsed again with ParseParticipants.
+// A zero network is taken from GONKA_NETWORK, or DefaultNetwork if unset.
+func FetchParticipants(ctx context.Context, baseURL string, epoch string, network Network) (*Participants, error) {
+	network, err := resolveNetwork(network)
+	if err != nil {
+		return nil, err
+	}
+	return getParticipantsWithProof(ctx, baseURL, epoch, network)
+}
+
+// ParseParticipants parses a payload returned by a node's participants call,
+// such as Participants.Raw, verifying its proof if the network requires it.
+func ParseParticipants(raw []byte, network Network) (*Participants, error) {
+	network, err := resolveNetwork(network)
+	if err != nil {
+		return nil, err
+	}
+	return parseParticipants(context.Background(), raw, network)
+}
+
+// Participants are the participants of an epoch as reported by a node.
+type Participants struct {
+	EpochID uint64
+	// BlockHeight is the height of the block the payload was proven at, if included.
+	BlockHeight          int64
+	PocStartBlockHeight  int64
+	EffectiveBlockHeight int64
+	CreatedAtBlockHeight int64
+	// Participants are all active participants, including excluded ones.
+	Participants []*ActiveParticipant
+	// Excluded are the addresses of the participants the chain excludes.
+	Excluded map[string]bool
+	// Verified reports whether the participants were checked against a proof.
+	Verified bool
+	// Raw is the payload as received.
+	Raw []byte
+}
+
+// Endpoints returns the endpoints of the participants that are not excluded.
+func (p *Participants) Endpoints() []Endpoint {
+	endpoints := make([]Endpoint, 0, len(p.Participants))
+	for _, participant := range p.Participants {
+		if !p.Excluded[participant.Index] {
+			endpoints = append(endpoints, participantEndpoint(participant))
+		}
+	}
+	return endpoints
+}
+
+// excludedEndpoints returns the endpoints of the participants that are excluded.
+func (p *Participants) excludedEndpoints() []Endpoint {
+	var endpoints []Endpoint
+	for _, participant := range p.Participants {
+		if p.Excluded[participant.Index] {
+			endpoints = append(endpoints, participantEndpoint(participant))
+		}
+	}
+	return endpoints
+}
+
+func participantEndpoint(participant *ActiveParticipant) Endpoint {
+	return Endpoint{
+		URL:     participant.InferenceUrl + "/v1",
+		Address: participant.Index,
+	}
+}
+
+func getParticipantsWithProof(ctx context.Context, baseURL string, epoch string, network Network) (participants *Participants, err error) {
+	ctx, span := startSpan(ctx, "gonka.GetParticipantsWithProof",
+		attribute.String("gonka.source_url", baseURL),
+		attribute.String("gonka.epoch", epoch),
+		attribute.Bool("gonka.proof_required", network.proofRequired()),
+	)
+	var endpoints []Endpoint
+	defer func() {
+		span.SetAttributes(attribute.Int("gonka.participants", len(endpoints)))
+		endSpan(span, err)
+	}()
+
+	participants, err = fetchParticipantsWithProof(ctx, baseURL, epoch, network)
+	var epochID uint64
+	if err != nil {
+		loggerFromContext(ctx).Warn("participant discovery failed", "source_url", baseURL, "epoch", epoch, errAttr(err))
+	} else {
+		endpoints = participants.Endpoints()
+		epochID = participants.EpochID
+		loggerFromContext(ctx).Info("participants resolved", "source_url", baseURL, "epoch", epoch,
+			"participants", len(endpoints), "verified", network.proofRequired())
+	}
+	metrics := metricsFromContext(ctx)
+	metrics.ObserveEpochRefresh(len(endpoints), err)
+	switch {
+	case errors.Is(err, ErrProofVerification):
+		metrics.ObserveProof(ProofKindParticipants, ProofFailed)
+	case err == nil && !network.proofRequired():
+		metrics.ObserveProof(ProofKindParticipants, ProofUnverified)
+	case err != nil:
+		metrics.ObserveProof(ProofKindParticipants, ProofFetchFailed)
+	default:
+		metrics.ObserveProof(ProofKindParticipants, ProofVerified)
+	}
+
+	hooks := hooksFromContext(ctx)
+	hooks.participantsResolved(ParticipantsResolvedEvent{
+		SourceURL: baseURL,
+		Epoch:     epoch,
+		EpochID:   epochID,
+		Endpoints: endpoints,
+		Verified:  err == nil && network.proofRequired(),
+		Err:       err,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, ep := range participants.excludedEndpoints() {
+		hooks.endpointQuarantined(EndpointQuarantinedEvent{Endpoint: ep, Reason: QuarantineReasonExcluded})
+	}
+	hooks.epochSeen(baseURL, epochID)
+	return participants, nil
+}
+
+func fetchParticipantsWithProof(ctx context.Context, baseURL string, epoch string, network Network) (*Participants, error) {
 	if epoch == "" {
 		return nil, ErrInvalidEpoch
 	}
@@ -32,7 +168,7 @@ func GetParticipantsWithProof(ctx context.Context, baseURL string, epoch string)
 	}
 
 	url := fmt.Sprintf("%s/v1/epochs/%v/participants", baseURL, epoch)
-	fmt.Println("url", url)
+	loggerFromContext(ctx).Debug("fetching participants", "url", url)
 
 	// Create a new HTTP request
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
@@ -61,10 +197,16 @@ func GetParticipantsWithProof(ctx context.Context, baseURL string, epoch string)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
+	return parseParticipants(ctx, bodyBytes, network)
+}
 
-	verify := os.Getenv("GONKA_VERIFY_PROOF") == "1"
+// parseParticipants decodes a participants payload, verifying its proof if the
+// network requires it.
+func parseParticipants(ctx context.Context, bodyBytes []byte, network Network) (*Participants, error) {
+	logger := loggerFromContext(ctx)
+	verify := network.proofRequired()
 
-	// Parse excluded_participants
+	// Parse excluded_participants and the block height, leniently as they are optional
 	var excludedRaw struct {
 		ExcludedParticipants []ExcludedParticipant `json:"excluded_participants"`
 	}
@@ -72,9 +214,19 @@ func GetParticipantsWithProof(ctx context.Context, baseURL string, epoch string)
 	excludedSet := make(map[string]bool, len(excludedRaw.ExcludedParticipants))
 	for _, ep := range excludedRaw.ExcludedParticipants {
 		excludedSet[ep.Address] = true
+		logger.Debug("participant excluded", "address", ep.Address)
+	}
+	var blockRaw struct {
+		Block struct {
+			Header struct {
+				Height json.Number `json:"height"`
+			} `json:"header"`
+		} `json:"block"`
 	}
+	_ = json.Unmarshal(bodyBytes, &blockRaw)
+	blockHeight, _ := blockRaw.Block.Header.Height.Int64()
 
-	var endpoints []Endpoint
+	var active ActiveParticipants
 	if verify {
 		// Full decode with verification
 		var participantResp ActiveParticipantWithProof
@@ -91,20 +243,13 @@ func GetParticipantsWithProof(ctx context.Context, baseURL string, epoch string)
 			return nil, fmt.Errorf("missing block/proof in response while verification is enabled")
 		}
 		if err := VerifyIAVLProofAgainstAppHash(participantResp.Block.AppHash, participantResp.ProofOps.Ops, val); err != nil {
-			return nil, fmt.Errorf("failed to verify participants proof: %w", err)
+			return nil, fmt.Errorf("%w: participants: %w", ErrProofVerification, err)
 		}
-
-		// Map to endpoints
-		endpoints = make([]Endpoint, 0, len(participantResp.ActiveParticipants.Participants))
-		for _, participant := range participantResp.ActiveParticipants.Participants {
-			if excludedSet[participant.Index] {
-				continue
-			}
-			endpoints = append(endpoints, Endpoint{
-				URL:     participant.InferenceUrl + "/v1",
-				Address: participant.Index,
-			})
+		if storeKey := string(participantResp.ProofOps.Ops[1].Key); network.ProofStoreKey != "" && storeKey != network.ProofStoreKey {
+			return nil, fmt.Errorf("%w: participants proof is for store %q, expected %q", ErrProofVerification, storeKey, network.ProofStoreKey)
 		}
+
+		active = participantResp.ActiveParticipants
 	} else {
 		// Light decode: ignore block/proof, just participants
 		var light struct {
@@ -113,18 +258,26 @@ func GetParticipantsWithProof(ctx context.Context, baseURL string, epoch string)
 		if err := json.Unmarshal(bodyBytes, &light); err != nil {
 			return nil, fmt.Errorf("failed to decode response (light): %w", err)
 		}
-		endpoints = make([]Endpoint, 0, len(light.ActiveParticipants.Participants))
-		for _, participant := range light.ActiveParticipants.Participants {
-			if excludedSet[participant.Index] {
-				continue
-			}
-			endpoints = append(endpoints, Endpoint{
-				URL:     participant.InferenceUrl + "/v1",
-				Address: participant.Index,
-			})
+		active = light.ActiveParticipants
+	}
+
+	participants := make([]*ActiveParticipant, 0, len(active.Participants))
+	for _, participant := range active.Participants {
+		if participant != nil {
+			participants = append(participants, participant)
 		}
 	}
-	return endpoints, nil
+	return &Participants{
+		EpochID:              active.EpochId,
+		BlockHeight:          blockHeight,
+		PocStartBlockHeight:  active.PocStartBlockHeight,
+		EffectiveBlockHeight: active.EffectiveBlockHeight,
+		CreatedAtBlockHeight: active.CreatedAtBlockHeight,
+		Participants:         participants,
+		Excluded:             excludedSet,
+		Verified:             verify,
+		Raw:                  bodyBytes,
+	}, nil
 }
 
 // VerifyIAVLProofAgainstAppHash verifies the correctness of an ABCIQuery response for ActiveParticipants.
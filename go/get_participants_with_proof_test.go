@@ -2,9 +2,15 @@ package gonkaopenai
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_GetParticipantsWithProof(t *testing.T) {
@@ -37,3 +43,78 @@ func Test_GetParticipants(t *testing.T) {
 	})
 	assert.NoError(t, err)
 }
+
+// newDiscoveryServer serves participants at srv.URL+"/<name>" by address, all
+// of them allowed transfer addresses without a proof, and the identity of each
+// participant from identities by name.
+func newDiscoveryServer(t *testing.T, participants map[string]string, identities map[string]string) *httptest.Server {
+	t.Helper()
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v1/epochs/current/participants":
+			var list []map[string]string
+			for name, address := range participants {
+				list = append(list, map[string]string{"index": address, "inference_url": srv.URL + "/" + name})
+			}
+			json.NewEncoder(w).Encode(map[string]any{"active_participants": map[string]any{"epoch_id": 1, "participants": list}})
+		case strings.HasSuffix(r.URL.Path, "/chain-api/productscience/inference/inference/params"):
+			var addresses []string
+			for _, address := range participants {
+				addresses = append(addresses, address)
+			}
+			json.NewEncoder(w).Encode(map[string]any{"params": map[string]any{
+				"transfer_agent_access_params": map[string]any{"allowed_transfer_addresses": addresses}}})
+		case strings.HasSuffix(r.URL.Path, "/v1/identity"):
+			name := strings.TrimPrefix(strings.TrimSuffix(r.URL.Path, "/v1/identity"), "/")
+			identity, ok := identities[name]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write([]byte(identity))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestDiscoverySkipsInvalidAddresses(t *testing.T) {
+	srv := newDiscoveryServer(t, map[string]string{"good": testTransferAddress, "bad": "gonka1notanaddress"}, nil)
+
+	var quarantined []EndpointQuarantinedEvent
+	g, err := NewGonkaOpenAI(Options{
+		GonkaPrivateKey: testPrivateKey,
+		SourceUrl:       srv.URL,
+		Hooks:           Hooks{OnEndpointQuarantined: func(e EndpointQuarantinedEvent) { quarantined = append(quarantined, e) }},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, srv.URL+"/good/v1", g.baseURL)
+	assert.Equal(t, []EndpointQuarantinedEvent{{
+		Endpoint: Endpoint{URL: srv.URL + "/bad/v1", Address: "gonka1notanaddress"},
+		Reason:   QuarantineReasonInvalidAddress,
+	}}, quarantined)
+
+	// Configured endpoints must all be valid
+	_, err = NewGonkaOpenAI(Options{
+		GonkaPrivateKey: testPrivateKey,
+		Endpoints:       []Endpoint{{URL: srv.URL + "/bad/v1", Address: "gonka1notanaddress"}},
+	})
+	assert.ErrorIs(t, err, ErrInvalidAddress)
+}
+
+func TestDiscoveryErrorIsReturned(t *testing.T) {
+	srv := newDiscoveryServer(t, map[string]string{"good": testTransferAddress}, nil)
+
+	// Mainnet requires a participants proof, which the node does not serve
+	_, err := NewGonkaOpenAI(Options{
+		GonkaPrivateKey: testPrivateKey,
+		SourceUrl:       srv.URL,
+		Network:         Mainnet,
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to get participants from "+srv.URL)
+	assert.NotContains(t, err.Error(), "no endpoints resolved")
+}
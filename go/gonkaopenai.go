@@ -2,13 +2,20 @@ package gonkaopenai
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
 	"os"
-	"strings"
+	"time"
 
+	"github.com/gonka-ai/gonka-openai/go/chainclient"
 	"github.com/openai/openai-go"
 	"github.com/openai/openai-go/option"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Endpoint represents a Gonka API endpoint with its associated transfer address
@@ -27,6 +34,49 @@ type Options struct {
 	OrgID                     string
 	SourceUrl                 string
 	Endpoints                 []Endpoint
+	// ClockSkewTolerance is the largest clock offset that is corrected automatically.
+	// See HTTPClientOptions.ClockSkewTolerance.
+	ClockSkewTolerance time.Duration
+	// Network selects the chain profile. Defaults to GONKA_NETWORK or DefaultNetwork.
+	Network Network
+	// AllowAddressMismatch permits a GonkaAddress that differs from the address of
+	// the private key, for delegated or authz setups. See HTTPClientOptions.
+	AllowAddressMismatch bool
+	// OnReceipt is called with the InferenceReceipt of every request. See also CaptureReceipt.
+	OnReceipt func(InferenceReceipt)
+	// Tokenizer counts prompt tokens for EstimateCost. Defaults to ApproximateTokenizer.
+	Tokenizer Tokenizer
+	// MaxRequestCost, if set, refuses chat completion requests whose estimated
+	// CostEstimate.MaxCost exceeds it, before they are signed, with ErrCostCeilingExceeded.
+	MaxRequestCost uint64
+	// Keys are additional requester accounts to sign with, picked per request by
+	// KeyPolicy. The GonkaPrivateKey account, if set, is the first key. Keys can
+	// be rotated at runtime through Keys().
+	Keys      []SigningKey
+	KeyPolicy KeyPolicy
+	// TracerProvider traces requests and endpoint discovery with OpenTelemetry
+	// spans. Defaults to the global provider. See HTTPClientOptions.TracerProvider.
+	TracerProvider trace.TracerProvider
+	// Propagator injects the trace context into requests. Defaults to W3C Trace Context.
+	Propagator propagation.TextMapPropagator
+	// Metrics, if set, receives request and discovery measurements. See the
+	// prommetrics package for a Prometheus implementation.
+	Metrics Metrics
+	// Logger, if set, receives discovery, endpoint selection and signing logs,
+	// with key material redacted. The client logs nothing by default.
+	Logger *slog.Logger
+	// Hooks are called on discovery, endpoint selection and request events.
+	Hooks Hooks
+	// Usage is the ledger token usage is recorded in. Defaults to a new ledger;
+	// see GonkaOpenAI.Usage.
+	Usage *UsageLedger
+	// Debug, or GONKA_DEBUG=1, dumps signed requests and their responses to
+	// DebugWriter, or os.Stderr. See HTTPClientOptions.Debug.
+	Debug       bool
+	DebugWriter io.Writer
+	// ForwardCallerTag forwards the caller tag set with WithCallerTag to
+	// participants as the OpenAI "user" field. See HTTPClientOptions.ForwardCallerTag.
+	ForwardCallerTag bool
 }
 
 // GonkaOpenAI wraps the official openai.Client.
@@ -34,17 +84,57 @@ type GonkaOpenAI struct {
 	*openai.Client
 	privateKey string
 	gonkaAddr  string
+	baseURL    string
+	network    Network
+	skew       *clockSkew
+	checks     *requestChecks
+	chain      *chainclient.Client
+	allowed    AllowedTransferAddresses
+	tokenizer  Tokenizer
+	prices     *priceCache
+	maxCost    uint64
+	keys       *KeyRing
+	usage      *UsageLedger
+	// discovery describes where the endpoints came from, for Status
+	sourceURL    string
+	participants *Participants
+	refreshedAt  time.Time
+	endpoints    []Endpoint
+	stats        *endpointStats
 }
 
 // NewGonkaOpenAI creates a new client configured for the Gonka network.
-func NewGonkaOpenAI(opts Options) (*GonkaOpenAI, error) {
+func NewGonkaOpenAI(opts Options) (_ *GonkaOpenAI, err error) {
 	privateKey := opts.GonkaPrivateKey
-	if privateKey == "" {
+	if privateKey == "" && len(opts.Keys) == 0 {
 		privateKey = os.Getenv(EnvPrivateKey)
 	}
+	if privateKey == "" && len(opts.Keys) > 0 {
+		privateKey = opts.Keys[0].PrivateKey
+	}
 	if privateKey == "" {
 		return nil, fmt.Errorf("private key must be provided via opts or %s", EnvPrivateKey)
 	}
+	if _, err := parsePrivateKey(privateKey); err != nil {
+		return nil, err
+	}
+	network, err := resolveNetwork(opts.Network)
+	if err != nil {
+		return nil, err
+	}
+
+	// Discovery spans are children of this span, traced by the configured provider
+	tracerProvider := opts.TracerProvider
+	if tracerProvider == nil {
+		tracerProvider = otel.GetTracerProvider()
+	}
+	ctx, span := tracerProvider.Tracer(instrumentationName).Start(context.Background(), "gonka.NewGonkaOpenAI")
+	defer func() { endSpan(span, err) }()
+	ctx = contextWithMetrics(ctx, opts.Metrics)
+	logger := newLogger(opts.Logger)
+	ctx = contextWithLogger(ctx, logger)
+	hooks := newHooks(opts.Hooks)
+	ctx = contextWithHooks(ctx, hooks)
 
 	// Determine endpoints per priority:
 	// 1) If opts.Endpoints provided -> use them directly (no filtering/identity)
@@ -53,6 +143,7 @@ func NewGonkaOpenAI(opts Options) (*GonkaOpenAI, error) {
 
 	var endpoints []Endpoint
 	var skipFilteringAndIdentity bool
+	var allowed AllowedTransferAddresses
 
 	// Check for explicitly provided endpoints first
 	if len(opts.Endpoints) > 0 {
@@ -62,7 +153,10 @@ func NewGonkaOpenAI(opts Options) (*GonkaOpenAI, error) {
 
 	// Check env endpoints if no explicit endpoints
 	if len(endpoints) == 0 {
-		envEndpoints := GetEndpointsFromEnv()
+		envEndpoints, err := ParseEndpoints(os.Getenv(EnvEndpoints))
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", EnvEndpoints, err)
+		}
 		if len(envEndpoints) > 0 {
 			endpoints = envEndpoints
 			skipFilteringAndIdentity = true
@@ -71,17 +165,22 @@ func NewGonkaOpenAI(opts Options) (*GonkaOpenAI, error) {
 
 	// Only use sourceUrl if no explicit endpoints
 	sourceUrl := ""
+	var participants *Participants
+	var refreshedAt time.Time
 	if len(endpoints) == 0 {
 		sourceUrl = opts.SourceUrl
 		if sourceUrl == "" {
 			sourceUrl = os.Getenv(EnvSourceUrl)
 		}
 		if sourceUrl != "" {
-			eps, err := GetParticipantsWithProof(context.Background(), sourceUrl, "current")
-			if err == nil && len(eps) > 0 {
-				endpoints = eps
+			p, err := getParticipantsWithProof(ctx, sourceUrl, "current", network)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get participants from %s: %w", sourceUrl, err)
 			}
+			participants = p
+			endpoints = p.Endpoints()
 		}
+		refreshedAt = time.Now()
 	}
 
 	if len(endpoints) == 0 {
@@ -91,22 +190,53 @@ func NewGonkaOpenAI(opts Options) (*GonkaOpenAI, error) {
 	// Only filter and fetch identity when using sourceUrl (not explicit endpoints)
 	if !skipFilteringAndIdentity && sourceUrl != "" {
 		// Filter by allowed_transfer_addresses
-		allowed, _ := FetchAllowedTransferAddresses(context.Background(), sourceUrl)
+		allowed = FetchVerifiedAllowedTransferAddresses(ctx, sourceUrl, network)
+		if err := checkAllowedTransferAddresses(allowed, network); err != nil {
+			return nil, err
+		}
+		if allowed.Status == ProofUnverified {
+			logger.Warn("allowed transfer addresses are not verified", errAttr(allowed.Err))
+		}
 		var filteredEndpoints []Endpoint
 		for _, ep := range endpoints {
-			if allowed[ep.Address] {
+			if allowed.Addresses[ep.Address] {
 				filteredEndpoints = append(filteredEndpoints, ep)
+			} else {
+				logger.Debug("endpoint not allowed", "url", ep.URL, "address", ep.Address)
+				hooks.endpointQuarantined(EndpointQuarantinedEvent{Endpoint: ep, Reason: QuarantineReasonNotAllowed})
 			}
 		}
+		if len(filteredEndpoints) == 0 {
+			return nil, fmt.Errorf("none of the %d participants is an allowed transfer address", len(endpoints))
+		}
 		endpoints = filteredEndpoints
 	}
 
-	// Validate that each endpoint has a non-empty address
+	// Validate that each endpoint has a well-formed address on this network.
+	// Configured endpoints must all be valid; a malformed participant from
+	// discovery is left out rather than failing the client.
+	validEndpoints := endpoints[:0:0]
 	for _, endpoint := range endpoints {
+		err := network.ValidateAddress(endpoint.Address)
 		if endpoint.Address == "" {
-			return nil, fmt.Errorf("endpoint %s has an empty address, all endpoints must have an address", endpoint.URL)
+			err = fmt.Errorf("endpoint %s has an empty address, all endpoints must have an address", endpoint.URL)
+		} else if err != nil {
+			err = fmt.Errorf("endpoint %s: %w", endpoint.URL, err)
+		}
+		switch {
+		case err == nil:
+			validEndpoints = append(validEndpoints, endpoint)
+		case skipFilteringAndIdentity:
+			return nil, err
+		default:
+			logger.Warn("participant skipped", "url", endpoint.URL, "address", endpoint.Address, errAttr(err))
+			hooks.endpointQuarantined(EndpointQuarantinedEvent{Endpoint: endpoint, Reason: QuarantineReasonInvalidAddress})
 		}
 	}
+	if len(validEndpoints) == 0 {
+		return nil, fmt.Errorf("none of the %d participants has a valid address", len(endpoints))
+	}
+	endpoints = validEndpoints
 
 	baseURL := ""
 	if opts.EndpointSelectionStrategy != nil {
@@ -124,48 +254,86 @@ func NewGonkaOpenAI(opts Options) (*GonkaOpenAI, error) {
 		}
 	}
 
-	// Only check for delegate_ta when using sourceUrl (not explicit endpoints)
+	logger.Info("endpoint selected", "url", baseURL, "address", selectedAddress, "candidates", len(endpoints))
+	selected := EndpointSelectedEvent{Endpoint: Endpoint{URL: baseURL, Address: selectedAddress}, Candidates: len(endpoints)}
+
+	// Only check for delegate_ta when using sourceUrl (not explicit endpoints).
+	// Delegate transfer agents replace the participant set only once the identity
+	// is signed by the participant and the delegates are verified against the
+	// chain. Otherwise requests keep going to the verified participant.
 	if !skipFilteringAndIdentity {
-		delegateTa, err := FetchNodeIdentity(context.Background(), baseURL)
+		participant := Endpoint{URL: baseURL, Address: selectedAddress}
+		delegateTa, err := FetchVerifiedNodeIdentity(ctx, baseURL, selectedAddress)
 		if err == nil && len(delegateTa) > 0 {
-			originalAddress := selectedAddress
+			chain := chainclient.New(sourceUrl, chainclient.Options{})
+			err = verifyDelegation(ctx, chain, participant, delegateTa, allowed)
+		}
+		switch {
+		case errors.Is(err, ErrDelegationUnverified):
+			logger.Warn("delegation refused", "participant", selectedAddress, errAttr(err))
+		case err != nil:
+			logger.Debug("node identity unavailable", "url", baseURL, errAttr(err))
+		case len(delegateTa) > 0:
 			if opts.EndpointSelectionStrategy != nil {
 				baseURL = CustomEndpointSelection(opts.EndpointSelectionStrategy, delegateTa)
 			} else {
 				baseURL = GonkaBaseURL(delegateTa)
 			}
+			// Requests are signed for the delegating participant, as before
+			// delegates were verified
 			for i := range delegateTa {
-				delegateTa[i].Address = originalAddress
+				delegateTa[i].Address = selectedAddress
 			}
 			endpoints = delegateTa
+			logger.Info("delegate override", "participant", selectedAddress, "delegates", len(delegateTa), "url", baseURL)
+			selected = EndpointSelectedEvent{
+				Endpoint:    Endpoint{URL: baseURL, Address: selectedAddress},
+				Candidates:  len(delegateTa),
+				Participant: selectedAddress,
+			}
 		}
 	}
+	hooks.endpointSelected(selected)
 
-	address := opts.GonkaAddress
-	if address == "" {
-		address = os.Getenv(EnvAddress)
-	}
-	if address == "" {
-		addr, err := GonkaAddress(privateKey)
-		if err == nil {
-			address = addr
-		} else {
-			prefix := strings.Split(GonkaChainID, "-")[0]
-			if len(privateKey) > 40 {
-				address = fmt.Sprintf("%s1%s", prefix, privateKey[:40])
-			} else {
-				address = fmt.Sprintf("%s1%s", prefix, privateKey)
-			}
+	// The configured or environment key is the primary key, followed by opts.Keys
+	keys := opts.Keys
+	if opts.GonkaPrivateKey != "" || len(keys) == 0 {
+		address := opts.GonkaAddress
+		if address == "" {
+			address = os.Getenv(EnvAddress)
 		}
+		keys = append([]SigningKey{{PrivateKey: privateKey, Address: address}}, opts.Keys...)
+	}
+	address, err := resolveRequesterAddress(network, keys[0].PrivateKey, keys[0].Address, opts.AllowAddressMismatch)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := opts.Usage
+	if usage == nil {
+		usage = NewUsageLedger()
 	}
 
 	// Create HTTP client with endpoints
-	httpClient, err := GonkaHTTPClient(HTTPClientOptions{
-		PrivateKey: privateKey,
-		Address:    address,
-		Endpoints:  endpoints,
-		Client:     opts.HTTPClient,
-	})
+	httpClient, err := gonkaHTTPClient(HTTPClientOptions{
+		Endpoints: endpoints,
+		Client:    opts.HTTPClient,
+		Network:   network,
+		Keys:      keys,
+		KeyPolicy: opts.KeyPolicy,
+
+		AllowAddressMismatch: opts.AllowAddressMismatch,
+		ClockSkewTolerance:   opts.ClockSkewTolerance,
+		OnReceipt:            opts.OnReceipt,
+		TracerProvider:       opts.TracerProvider,
+		Propagator:           opts.Propagator,
+		Metrics:              opts.Metrics,
+		Logger:               opts.Logger,
+		Usage:                usage,
+		Debug:                opts.Debug,
+		DebugWriter:          opts.DebugWriter,
+		ForwardCallerTag:     opts.ForwardCallerTag,
+	}, hooks)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create HTTP client: %w", err)
 	}
@@ -185,14 +353,98 @@ func NewGonkaOpenAI(opts Options) (*GonkaOpenAI, error) {
 	}
 
 	rawClient := openai.NewClient(clientOptions...)
-	return &GonkaOpenAI{Client: &rawClient, privateKey: privateKey, gonkaAddr: address}, nil
+	// Chain queries go through the source node when there is one, otherwise the selected endpoint
+	chainURL := sourceUrl
+	if chainURL == "" {
+		chainURL = baseURL
+	}
+
+	chain := chainclient.New(chainURL, chainclient.Options{})
+	g := &GonkaOpenAI{
+		Client:     &rawClient,
+		privateKey: privateKey,
+		gonkaAddr:  address,
+		baseURL:    baseURL,
+		network:    network,
+		chain:      chain,
+		allowed:    allowed,
+		tokenizer:  opts.Tokenizer,
+		prices:     newPriceCache(chain, 0),
+		maxCost:    opts.MaxRequestCost,
+		usage:      usage,
+
+		sourceURL:    sourceUrl,
+		participants: participants,
+		refreshedAt:  refreshedAt,
+	}
+	if rt, ok := httpClient.Transport.(signingRoundTripper); ok {
+		g.skew = rt.skew
+		g.checks = rt.checks
+		g.keys = rt.keys
+		g.endpoints = rt.endpoints
+		g.stats = rt.stats
+	}
+	if g.maxCost > 0 && g.checks != nil {
+		g.checks.add(g.costCheck)
+	}
+	return g, nil
 }
 
-// GonkaAddress returns the configured Gonka address.
-func (g *GonkaOpenAI) GonkaAddress() string { return g.gonkaAddr }
+// GonkaAddress returns the requester address of the primary key, the first
+// key of Keys. It is empty if all keys have been removed.
+func (g *GonkaOpenAI) GonkaAddress() string {
+	if g.keys == nil {
+		return g.gonkaAddr
+	}
+	_, address, _ := g.keys.primary()
+	return address
+}
 
 // PrivateKey returns the private key used for signing.
-func (g *GonkaOpenAI) PrivateKey() string { return g.privateKey }
+// With several keys it is the primary key, the first key of Keys.
+func (g *GonkaOpenAI) PrivateKey() string {
+	if g.keys == nil {
+		return g.privateKey
+	}
+	privateKey, _, _ := g.keys.primary()
+	return privateKey
+}
+
+// Keys returns the signing keys, which can be rotated at runtime.
+func (g *GonkaOpenAI) Keys() *KeyRing { return g.keys }
+
+// Usage returns the ledger of the token usage reported in responses.
+func (g *GonkaOpenAI) Usage() *UsageLedger { return g.usage }
+
+// Network returns the network profile the client was created for.
+func (g *GonkaOpenAI) Network() Network { return g.network }
+
+// AllowedTransferAddresses returns the allowed transfer addresses the endpoints
+// were filtered by, and whether they were verified. It is zero when endpoints
+// were configured explicitly.
+func (g *GonkaOpenAI) AllowedTransferAddresses() AllowedTransferAddresses { return g.allowed }
+
+// Chain returns a client for chain queries through the node the client was configured with.
+func (g *GonkaOpenAI) Chain() *chainclient.Client { return g.chain }
+
+// ClockSkew returns how far a participant's clock is ahead of the local clock,
+// as last measured from any participant, and whether a measurement has been made yet.
+func (g *GonkaOpenAI) ClockSkew() (time.Duration, bool) { return g.skew.Offset() }
+
+// SyncClock measures the clock offset against the selected endpoint's identity
+// call and uses it to correct subsequent signing timestamps for that endpoint.
+// It returns a *ClockSkewError if the offset exceeds the configured tolerance.
+func (g *GonkaOpenAI) SyncClock(ctx context.Context) error {
+	if g.skew == nil {
+		return nil
+	}
+	offset, err := MeasureClockSkew(ctx, g.baseURL)
+	if err != nil {
+		return err
+	}
+	g.skew.set(g.baseURL, offset)
+	return g.skew.check(g.baseURL)
+}
 
 // ExampleChatCompletion demonstrates a simple call using the Gonka client.
 func ExampleChatCompletion(ctx context.Context, g *GonkaOpenAI) (*openai.ChatCompletion, error) {
@@ -203,3 +455,19 @@ func ExampleChatCompletion(ctx context.Context, g *GonkaOpenAI) (*openai.ChatCom
 		},
 	})
 }
+
+// checkAllowedTransferAddresses returns an error if allowed cannot be used to
+// filter the endpoints discovered on network. A list left unverified because
+// the network profile does not configure its proof is accepted even when proofs
+// are required, so that a missing TransferAgentParamsField does not block
+// clients.
+func checkAllowedTransferAddresses(allowed AllowedTransferAddresses, network Network) error {
+	switch {
+	case allowed.Status == ProofFetchFailed:
+		return fmt.Errorf("failed to fetch allowed transfer addresses: %w", allowed.Err)
+	case allowed.Status == ProofFailed,
+		allowed.Status == ProofUnverified && network.proofRequired() && !errors.Is(allowed.Err, ErrProofNotConfigured):
+		return fmt.Errorf("failed to verify allowed transfer addresses: %w", allowed.Err)
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package gonkaopenai
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Hooks are callbacks for events in the client's lifecycle, for example to feed
+// alerting or analytics. Any of them may be nil. They are called synchronously
+// from discovery and from the transport, possibly concurrently, so they must be
+// safe for concurrent use and return quickly.
+type Hooks struct {
+	// OnParticipantsResolved is called after each attempt to fetch the
+	// participants of an epoch, with Err set if it failed.
+	OnParticipantsResolved func(ParticipantsResolvedEvent)
+	// OnEndpointSelected is called with the endpoint the client sends requests to.
+	OnEndpointSelected func(EndpointSelectedEvent)
+	// OnRequestSigned is called for each attempt of a request once it is signed,
+	// before it is sent.
+	OnRequestSigned func(RequestSignedEvent)
+	// OnRetry is called for each retried attempt of a request, before it is signed.
+	OnRetry func(RetryEvent)
+	// OnEpochChanged is called when discovery returns the participants of an
+	// epoch other than the one last seen, including the first epoch seen.
+	// Discovery only runs when the client is created, so for a client it is
+	// called once, with the epoch it started in; it does not follow later epochs.
+	OnEpochChanged func(EpochChangedEvent)
+	// OnEndpointQuarantined is called for each participant that discovery leaves
+	// out of the endpoints requests are sent to. Like OnEpochChanged it is only
+	// called while the client is created.
+	OnEndpointQuarantined func(EndpointQuarantinedEvent)
+	// OnResponse is called for each attempt of a request when its response
+	// headers arrive or the attempt fails.
+	OnResponse func(ResponseEvent)
+}
+
+// ParticipantsResolvedEvent describes a fetch of the participants of an epoch.
+type ParticipantsResolvedEvent struct {
+	SourceURL string
+	// Epoch is the epoch that was asked for, such as "current".
+	Epoch string
+	// EpochID is the epoch the participants belong to, if the node reported it.
+	EpochID   uint64
+	Endpoints []Endpoint
+	// Verified reports whether the participants were checked against a proof.
+	Verified bool
+	Err      error
+}
+
+// EndpointSelectedEvent describes the endpoint chosen for a client.
+type EndpointSelectedEvent struct {
+	Endpoint Endpoint
+	// Candidates is the number of endpoints the selection was made from.
+	Candidates int
+	// Participant is the address of the participant that delegated to Endpoint,
+	// if Endpoint is a delegate transfer agent.
+	Participant string
+}
+
+// RequestSignedEvent describes a signed attempt of a request.
+type RequestSignedEvent struct {
+	URL              string
+	Endpoint         string
+	TransferAddress  string
+	RequesterAddress string
+	Model            string
+	CallerTag        string
+	// Timestamp is the X-Timestamp the request was signed with.
+	Timestamp int64
+	Attempt   int
+}
+
+// RetryEvent describes a retried attempt of a request.
+type RetryEvent struct {
+	URL       string
+	Endpoint  string
+	Model     string
+	CallerTag string
+	// Attempt counts the retries, starting at 1.
+	Attempt int
+}
+
+// EpochChangedEvent describes a change of the epoch participants are taken from.
+type EpochChangedEvent struct {
+	SourceURL string
+	// Previous is zero if no epoch had been seen before.
+	Previous uint64
+	Current  uint64
+}
+
+// Reasons reported in EndpointQuarantinedEvent.Reason.
+const (
+	// QuarantineReasonExcluded means the chain lists the participant as excluded.
+	QuarantineReasonExcluded = "excluded"
+	// QuarantineReasonNotAllowed means the participant is not an allowed transfer address.
+	QuarantineReasonNotAllowed = "not_allowed"
+	// QuarantineReasonInvalidAddress means the participant's address is not a
+	// valid address on the network.
+	QuarantineReasonInvalidAddress = "invalid_address"
+)
+
+// EndpointQuarantinedEvent describes a participant left out of the endpoints.
+type EndpointQuarantinedEvent struct {
+	Endpoint Endpoint
+	// Reason is one of the QuarantineReason constants.
+	Reason string
+}
+
+// ResponseEvent describes the outcome of an attempt of a request.
+type ResponseEvent struct {
+	URL              string
+	Endpoint         string
+	RequesterAddress string
+	Model            string
+	CallerTag        string
+	Attempt          int
+	// StatusCode is zero if no response was received, in which case Err is set.
+	StatusCode int
+	Err        error
+	// Duration is the time until the response headers arrived or the attempt failed.
+	Duration time.Duration
+}
+
+// hooks calls the configured Hooks and keeps the state events are derived from.
+// A nil *hooks calls nothing.
+type hooks struct {
+	Hooks
+	mu    sync.Mutex
+	epoch uint64
+}
+
+func newHooks(h Hooks) *hooks { return &hooks{Hooks: h} }
+
+func (h *hooks) participantsResolved(e ParticipantsResolvedEvent) {
+	if h != nil && h.OnParticipantsResolved != nil {
+		h.OnParticipantsResolved(e)
+	}
+}
+
+func (h *hooks) endpointSelected(e EndpointSelectedEvent) {
+	if h != nil && h.OnEndpointSelected != nil {
+		h.OnEndpointSelected(e)
+	}
+}
+
+func (h *hooks) requestSigned(e RequestSignedEvent) {
+	if h != nil && h.OnRequestSigned != nil {
+		h.OnRequestSigned(e)
+	}
+}
+
+func (h *hooks) retry(e RetryEvent) {
+	if h != nil && h.OnRetry != nil {
+		h.OnRetry(e)
+	}
+}
+
+func (h *hooks) endpointQuarantined(e EndpointQuarantinedEvent) {
+	if h != nil && h.OnEndpointQuarantined != nil {
+		h.OnEndpointQuarantined(e)
+	}
+}
+
+func (h *hooks) response(e ResponseEvent) {
+	if h != nil && h.OnResponse != nil {
+		h.OnResponse(e)
+	}
+}
+
+// epochSeen records that participants of epoch were returned by sourceURL and
+// reports a change. Unknown epochs, reported as zero, are ignored.
+func (h *hooks) epochSeen(sourceURL string, epoch uint64) {
+	if h == nil || epoch == 0 {
+		return
+	}
+	h.mu.Lock()
+	previous := h.epoch
+	h.epoch = epoch
+	h.mu.Unlock()
+	if previous != epoch && h.OnEpochChanged != nil {
+		h.OnEpochChanged(EpochChangedEvent{SourceURL: sourceURL, Previous: previous, Current: epoch})
+	}
+}
+
+type hooksKey struct{}
+
+// contextWithHooks makes discovery steps run with ctx report events to h.
+func contextWithHooks(ctx context.Context, h *hooks) context.Context {
+	return context.WithValue(ctx, hooksKey{}, h)
+}
+
+func hooksFromContext(ctx context.Context) *hooks {
+	h, _ := ctx.Value(hooksKey{}).(*hooks)
+	return h
+}
+
+// retryAttempt returns how many times req has been retried, as reported by the
+// OpenAI client's retry header.
+func retryAttempt(req *http.Request) int {
+	attempt, _ := strconv.Atoi(req.Header.Get("X-Stainless-Retry-Count"))
+	return attempt
+}
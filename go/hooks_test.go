@@ -0,0 +1,120 @@
+package gonkaopenai
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/openai/openai-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscoveryHooks(t *testing.T) {
+	other := mustAddress(t, testOtherPrivateKey)
+	epoch := 7
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"active_participants":{"epoch_id":%d,"participants":[`+
+			`{"index":%q,"inference_url":"http://a.test"},{"index":%q,"inference_url":"http://b.test"}]},`+
+			`"excluded_participants":[{"address":%q}]}`, epoch, testTransferAddress, other, other)
+	}))
+	defer srv.Close()
+
+	var resolved []ParticipantsResolvedEvent
+	var quarantined []EndpointQuarantinedEvent
+	var changes []EpochChangedEvent
+	ctx := contextWithHooks(context.Background(), newHooks(Hooks{
+		OnParticipantsResolved: func(e ParticipantsResolvedEvent) { resolved = append(resolved, e) },
+		OnEndpointQuarantined:  func(e EndpointQuarantinedEvent) { quarantined = append(quarantined, e) },
+		OnEpochChanged:         func(e EpochChangedEvent) { changes = append(changes, e) },
+	}))
+
+	for _, e := range []int{7, 7, 8} {
+		epoch = e
+		_, err := getParticipantsWithProof(ctx, srv.URL, "current", Testnet)
+		require.NoError(t, err)
+	}
+
+	require.Len(t, resolved, 3)
+	assert.Equal(t, uint64(7), resolved[0].EpochID)
+	assert.Equal(t, []Endpoint{{URL: "http://a.test/v1", Address: testTransferAddress}}, resolved[0].Endpoints)
+	assert.False(t, resolved[0].Verified)
+	require.Len(t, quarantined, 3)
+	assert.Equal(t, EndpointQuarantinedEvent{
+		Endpoint: Endpoint{URL: "http://b.test/v1", Address: other},
+		Reason:   QuarantineReasonExcluded,
+	}, quarantined[0])
+	assert.Equal(t, []EpochChangedEvent{
+		{SourceURL: srv.URL, Previous: 0, Current: 7},
+		{SourceURL: srv.URL, Previous: 7, Current: 8},
+	}, changes)
+}
+
+func TestRequestHooks(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"chatcmpl-1","object":"chat.completion","model":"m","choices":[]}`))
+	}))
+	defer srv.Close()
+
+	var mu sync.Mutex
+	var selected []EndpointSelectedEvent
+	var signed []RequestSignedEvent
+	var retries []RetryEvent
+	var responses []ResponseEvent
+	endpoint := Endpoint{URL: srv.URL + "/v1", Address: testTransferAddress}
+	g, err := NewGonkaOpenAI(Options{
+		GonkaPrivateKey: testPrivateKey,
+		Endpoints:       []Endpoint{endpoint},
+		Hooks: Hooks{
+			OnEndpointSelected: func(e EndpointSelectedEvent) { selected = append(selected, e) },
+			OnRequestSigned: func(e RequestSignedEvent) {
+				mu.Lock()
+				defer mu.Unlock()
+				signed = append(signed, e)
+			},
+			OnRetry: func(e RetryEvent) {
+				mu.Lock()
+				defer mu.Unlock()
+				retries = append(retries, e)
+			},
+			OnResponse: func(e ResponseEvent) {
+				mu.Lock()
+				defer mu.Unlock()
+				responses = append(responses, e)
+			},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []EndpointSelectedEvent{{Endpoint: endpoint, Candidates: 1}}, selected)
+
+	_, err = g.Chat.Completions.New(context.Background(), openai.ChatCompletionNewParams{
+		Model:    "m",
+		Messages: []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hi")},
+	})
+	require.NoError(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, signed, 2)
+	assert.Equal(t, endpoint.URL, signed[0].Endpoint)
+	assert.Equal(t, testTransferAddress, signed[0].TransferAddress)
+	assert.Equal(t, g.GonkaAddress(), signed[0].RequesterAddress)
+	assert.Equal(t, "m", signed[0].Model)
+	assert.NotZero(t, signed[0].Timestamp)
+	assert.Equal(t, 1, signed[1].Attempt)
+	assert.Equal(t, []RetryEvent{{URL: srv.URL + "/v1/chat/completions", Endpoint: endpoint.URL, Model: "m", Attempt: 1}}, retries)
+	require.Len(t, responses, 2)
+	assert.Equal(t, http.StatusServiceUnavailable, responses[0].StatusCode)
+	assert.Equal(t, http.StatusOK, responses[1].StatusCode)
+	assert.NoError(t, responses[1].Err)
+}
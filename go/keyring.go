@@ -0,0 +1,208 @@
+package gonkaopenai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrNoSigningKey is returned when no signing key is available for a request.
+var ErrNoSigningKey = errors.New("no signing key available")
+
+// SigningKey is a requester account the client can sign requests with.
+type SigningKey struct {
+	PrivateKey string
+	// Address is the requester address sent with requests signed by this key.
+	// Defaults to the key's own address.
+	Address string
+	// Tenant is the caller tag, set with WithCallerTag, of the requests the key
+	// signs under KeyPolicyPerTenant.
+	Tenant string
+}
+
+// KeyPolicy decides which signing key a request is signed with.
+type KeyPolicy int
+
+const (
+	// KeyPolicyRoundRobin cycles through the keys in order.
+	KeyPolicyRoundRobin KeyPolicy = iota
+	// KeyPolicyLeastSpent picks the key with the least spend, and among equals
+	// the one that signed the fewest requests. Spend is the billed cost of the
+	// inferences confirmed with GonkaOpenAI.LookupInference, plus any recorded
+	// with KeyRing.RecordSpend; until some is recorded, it picks the key that
+	// signed the fewest requests.
+	KeyPolicyLeastSpent
+	// KeyPolicyPerTenant picks the key whose Tenant is the caller tag set on the
+	// request context with WithCallerTag. Requests without a matching key use a
+	// key with no Tenant.
+	KeyPolicyPerTenant
+)
+
+func (p KeyPolicy) String() string {
+	switch p {
+	case KeyPolicyRoundRobin:
+		return "round-robin"
+	case KeyPolicyLeastSpent:
+		return "least-spent"
+	case KeyPolicyPerTenant:
+		return "per-tenant"
+	}
+	return "unknown"
+}
+
+// KeyRing holds the signing keys of a client and picks one per request. Keys can
+// be added and removed at runtime; requests already signed are not affected.
+// It is safe for concurrent use.
+type KeyRing struct {
+	network       Network
+	policy        KeyPolicy
+	allowMismatch bool
+
+	mu   sync.Mutex
+	keys []*ringKey
+	next int
+}
+
+type ringKey struct {
+	privateKey string
+	address    string
+	tenant     string
+	spent      uint64
+	requests   uint64
+}
+
+func newKeyRing(network Network, policy KeyPolicy, allowMismatch bool, keys []SigningKey) (*KeyRing, error) {
+	r := &KeyRing{network: network, policy: policy, allowMismatch: allowMismatch}
+	for _, k := range keys {
+		if err := r.Add(k); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Policy returns the policy keys are picked by.
+func (r *KeyRing) Policy() KeyPolicy { return r.policy }
+
+// Add validates key and makes it available for signing. Adding a key whose
+// requester address is already present replaces it.
+func (r *KeyRing) Add(key SigningKey) error {
+	if _, err := parsePrivateKey(key.PrivateKey); err != nil {
+		return err
+	}
+	address, err := resolveRequesterAddress(r.network, key.PrivateKey, key.Address, r.allowMismatch)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, k := range r.keys {
+		if k.address == address {
+			k.privateKey = key.PrivateKey
+			k.tenant = key.Tenant
+			return nil
+		}
+	}
+	r.keys = append(r.keys, &ringKey{privateKey: key.PrivateKey, address: address, tenant: key.Tenant})
+	return nil
+}
+
+// Remove stops signing with the key of address. It reports whether the key was present.
+func (r *KeyRing) Remove(address string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, k := range r.keys {
+		if k.address == address {
+			r.keys = append(r.keys[:i], r.keys[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Addresses returns the requester addresses of the keys, in order.
+func (r *KeyRing) Addresses() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	addresses := make([]string, len(r.keys))
+	for i, k := range r.keys {
+		addresses[i] = k.address
+	}
+	return addresses
+}
+
+// RecordSpend adds amount to the spend of the key of address, as used by
+// KeyPolicyLeastSpent. LookupInference records the billed cost itself; use
+// RecordSpend for spend observed elsewhere, such as balance changes.
+func (r *KeyRing) RecordSpend(address string, amount uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, k := range r.keys {
+		if k.address == address {
+			k.spent += amount
+			return
+		}
+	}
+}
+
+// Spent returns the spend recorded for the key of address.
+func (r *KeyRing) Spent(address string) uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, k := range r.keys {
+		if k.address == address {
+			return k.spent
+		}
+	}
+	return 0
+}
+
+// primary returns the private key and requester address of the first key.
+func (r *KeyRing) primary() (string, string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.keys) == 0 {
+		return "", "", false
+	}
+	return r.keys[0].privateKey, r.keys[0].address, true
+}
+
+// pick returns the private key and requester address to sign a request with.
+func (r *KeyRing) pick(ctx context.Context) (string, string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.keys) == 0 {
+		return "", "", ErrNoSigningKey
+	}
+
+	var key *ringKey
+	switch r.policy {
+	case KeyPolicyLeastSpent:
+		for _, k := range r.keys {
+			if key == nil || k.spent < key.spent || (k.spent == key.spent && k.requests < key.requests) {
+				key = k
+			}
+		}
+	case KeyPolicyPerTenant:
+		tenant, _ := CallerTagFromContext(ctx)
+		for _, k := range r.keys {
+			if k.tenant == tenant {
+				key = k
+				break
+			}
+			if key == nil && k.tenant == "" {
+				key = k
+			}
+		}
+		if key == nil {
+			return "", "", fmt.Errorf("%w: no key for tenant %q", ErrNoSigningKey, tenant)
+		}
+	default:
+		key = r.keys[r.next%len(r.keys)]
+		r.next = (r.next + 1) % len(r.keys)
+	}
+	key.requests++
+	return key.privateKey, key.address, nil
+}
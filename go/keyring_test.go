@@ -0,0 +1,88 @@
+package gonkaopenai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openai/openai-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyRingPolicies(t *testing.T) {
+	a, b := mustAddress(t, testPrivateKey), mustAddress(t, testOtherPrivateKey)
+	keys := []SigningKey{{PrivateKey: testPrivateKey}, {PrivateKey: testOtherPrivateKey, Tenant: "acme"}}
+
+	ring, err := newKeyRing(Testnet, KeyPolicyRoundRobin, false, keys)
+	require.NoError(t, err)
+	var picked []string
+	for i := 0; i < 3; i++ {
+		_, addr, err := ring.pick(context.Background())
+		require.NoError(t, err)
+		picked = append(picked, addr)
+	}
+	assert.Equal(t, []string{a, b, a}, picked)
+
+	ring, err = newKeyRing(Testnet, KeyPolicyLeastSpent, false, keys)
+	require.NoError(t, err)
+	ring.RecordSpend(a, 100)
+	for i := 0; i < 2; i++ {
+		_, addr, err := ring.pick(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, b, addr)
+	}
+	assert.Equal(t, uint64(100), ring.Spent(a))
+
+	ring, err = newKeyRing(Testnet, KeyPolicyPerTenant, false, keys)
+	require.NoError(t, err)
+	_, addr, err := ring.pick(WithCallerTag(context.Background(), "acme"))
+	require.NoError(t, err)
+	assert.Equal(t, b, addr)
+	_, addr, err = ring.pick(WithCallerTag(context.Background(), "other"))
+	require.NoError(t, err)
+	assert.Equal(t, a, addr, "unknown tenants use the key without a tenant")
+
+	require.True(t, ring.Remove(a))
+	_, _, err = ring.pick(context.Background())
+	assert.ErrorIs(t, err, ErrNoSigningKey)
+
+	_, err = newKeyRing(Testnet, KeyPolicyRoundRobin, false, []SigningKey{{PrivateKey: testPrivateKey, Address: b}})
+	assert.ErrorIs(t, err, ErrKeyAddressMismatch)
+}
+
+func TestKeyRotation(t *testing.T) {
+	var requesters []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, VerifyRequest(r, nil, testTransferAddress))
+		requesters = append(requesters, r.Header.Get("X-Requester-Address"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"chatcmpl-1","object":"chat.completion","model":"m","choices":[]}`))
+	}))
+	defer srv.Close()
+
+	g, err := NewGonkaOpenAI(Options{
+		Keys:      []SigningKey{{PrivateKey: testPrivateKey}},
+		Endpoints: []Endpoint{{URL: srv.URL + "/v1", Address: testTransferAddress}},
+	})
+	require.NoError(t, err)
+	a := mustAddress(t, testPrivateKey)
+	assert.Equal(t, a, g.GonkaAddress())
+
+	params := openai.ChatCompletionNewParams{
+		Model:    "m",
+		Messages: []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hi")},
+	}
+	_, err = g.Chat.Completions.New(context.Background(), params)
+	require.NoError(t, err)
+
+	require.NoError(t, g.Keys().Add(SigningKey{PrivateKey: testOtherPrivateKey}))
+	require.True(t, g.Keys().Remove(a))
+	_, err = g.Chat.Completions.New(context.Background(), params)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{a, mustAddress(t, testOtherPrivateKey)}, requesters)
+	assert.Equal(t, mustAddress(t, testOtherPrivateKey), g.GonkaAddress())
+	assert.Equal(t, testOtherPrivateKey, g.PrivateKey())
+}
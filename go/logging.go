@@ -0,0 +1,111 @@
+package gonkaopenai
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+)
+
+// The library logs nothing unless a logger is configured with Options.Logger or
+// HTTPClientOptions.Logger. Configured loggers are wrapped so that attributes
+// named like key material are redacted.
+
+// discardLogger is used when no logger is configured.
+var discardLogger = slog.New(discardHandler{})
+
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (d discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return d }
+func (d discardHandler) WithGroup(string) slog.Handler           { return d }
+
+// newLogger returns the logger the library logs to: l with redaction, or a
+// logger that discards everything if l is nil.
+func newLogger(l *slog.Logger) *slog.Logger {
+	if l == nil {
+		return discardLogger
+	}
+	if _, ok := l.Handler().(redactingHandler); ok {
+		return l
+	}
+	return slog.New(redactingHandler{l.Handler()})
+}
+
+// redactedValue replaces the value of sensitive attributes.
+const redactedValue = "[REDACTED]"
+
+// sensitiveKeys are attribute keys, compared case-insensitively, whose values are never logged.
+var sensitiveKeys = map[string]bool{
+	"private_key":   true,
+	"privatekey":    true,
+	"authorization": true,
+	"signature":     true,
+	"api_key":       true,
+	"apikey":        true,
+}
+
+func redactAttr(a slog.Attr) slog.Attr {
+	if sensitiveKeys[strings.ToLower(a.Key)] {
+		return slog.String(a.Key, redactedValue)
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		group := a.Value.Group()
+		attrs := make([]any, len(group))
+		for i, ga := range group {
+			attrs[i] = redactAttr(ga)
+		}
+		return slog.Group(a.Key, attrs...)
+	}
+	return a
+}
+
+// redactingHandler redacts sensitive attributes before passing records on.
+type redactingHandler struct {
+	slog.Handler
+}
+
+func (h redactingHandler) Handle(ctx context.Context, r slog.Record) error {
+	redacted := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		redacted.AddAttrs(redactAttr(a))
+		return true
+	})
+	return h.Handler.Handle(ctx, redacted)
+}
+
+func (h redactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = redactAttr(a)
+	}
+	return redactingHandler{h.Handler.WithAttrs(redacted)}
+}
+
+func (h redactingHandler) WithGroup(name string) slog.Handler {
+	return redactingHandler{h.Handler.WithGroup(name)}
+}
+
+// errAttr returns err as a log attribute. Errors about an invalid private key
+// are reduced to ErrInvalidPrivateKey, as their details can quote the key.
+func errAttr(err error) slog.Attr {
+	if errors.Is(err, ErrInvalidPrivateKey) {
+		return slog.String("error", ErrInvalidPrivateKey.Error())
+	}
+	return slog.Any("error", err)
+}
+
+type loggerKey struct{}
+
+// contextWithLogger makes discovery steps run with ctx log to l.
+func contextWithLogger(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+func loggerFromContext(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok && l != nil {
+		return l
+	}
+	return discardLogger
+}
@@ -0,0 +1,55 @@
+package gonkaopenai
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLogger(buf *bytes.Buffer) *slog.Logger {
+	return newLogger(slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+}
+
+func TestLoggerRedactsKeyMaterial(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newTestLogger(&buf)
+	logger.With("private_key", testPrivateKey).Info("configured",
+		"Authorization", "c2lnbmF0dXJl",
+		slog.Group("request", "signature", "c2lnbmF0dXJl", "model", "m"),
+	)
+	out := buf.String()
+	assert.NotContains(t, out, testPrivateKey)
+	assert.NotContains(t, out, "c2lnbmF0dXJl")
+	assert.Contains(t, out, redactedValue)
+	assert.Contains(t, out, `"model":"m"`)
+
+	assert.Same(t, logger, newLogger(logger), "loggers are wrapped once")
+	assert.False(t, newLogger(nil).Enabled(context.Background(), slog.LevelError), "silent by default")
+}
+
+func TestSigningFailureLogged(t *testing.T) {
+	var buf bytes.Buffer
+	rt := signingRoundTripper{
+		rt: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+		}),
+		keys:      testKeyRing(t, "zz-secret"),
+		endpoints: []Endpoint{{URL: "http://participant.test/v1", Address: testTransferAddress}},
+		logger:    newTestLogger(&buf),
+	}
+	req, err := http.NewRequest(http.MethodGet, "http://participant.test/v1/models", nil)
+	require.NoError(t, err)
+	_, err = rt.RoundTrip(req)
+	require.ErrorIs(t, err, ErrInvalidPrivateKey)
+
+	out := buf.String()
+	assert.Contains(t, out, "request signing failed")
+	assert.Contains(t, out, ErrInvalidPrivateKey.Error())
+	assert.NotContains(t, out, "zz-secret")
+	assert.NotContains(t, out, "U+007A")
+}
@@ -0,0 +1,188 @@
+package gonkaopenai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Metrics receives measurements from the client, for example to export them to
+// Prometheus with the prommetrics package. Implementations must be safe for
+// concurrent use. Embed NoopMetrics to implement only some of the methods.
+type Metrics interface {
+	// ObserveRequest is called once per attempt, when its response body has been
+	// consumed or the attempt has failed.
+	ObserveRequest(RequestObservation)
+	// ObserveRetry is called for each retried attempt of a request.
+	ObserveRetry(endpoint, model string)
+	// ObserveProof is called with the outcome of each proof-verified discovery
+	// step; kind is ProofKindParticipants or ProofKindAllowedTransferAddresses.
+	ObserveProof(kind string, status ProofStatus)
+	// ObserveEpochRefresh is called each time the participants of an epoch are fetched.
+	ObserveEpochRefresh(participants int, err error)
+	// ObserveTokenUsage is called with the usage reported in a response, if any.
+	ObserveTokenUsage(endpoint, model string, usage TokenUsage)
+}
+
+// Proof kinds passed to Metrics.ObserveProof.
+const (
+	ProofKindParticipants             = "participants"
+	ProofKindAllowedTransferAddresses = "allowed_transfer_addresses"
+)
+
+// Error classes reported in RequestObservation.ErrorClass.
+const (
+	ErrorClassSigning             = "signing"
+	ErrorClassClockSkew           = "clock_skew"
+	ErrorClassInsufficientBalance = "insufficient_balance"
+	ErrorClassCostCeiling         = "cost_ceiling"
+	ErrorClassTimeout             = "timeout"
+	ErrorClassCanceled            = "canceled"
+	ErrorClassTransport           = "transport"
+	ErrorClassUnauthorized        = "unauthorized"
+	ErrorClassRateLimited         = "rate_limited"
+	ErrorClassClient              = "client_error"
+	ErrorClassServer              = "server_error"
+)
+
+// RequestObservation describes one attempt of a request.
+type RequestObservation struct {
+	Endpoint string
+	Model    string
+	// CallerTag is the tag set with WithCallerTag, if any.
+	CallerTag string
+	// StatusCode is zero if no response was received.
+	StatusCode int
+	// ErrorClass is empty for successful attempts, otherwise one of the ErrorClass constants.
+	ErrorClass string
+	// Attempt is zero for the first attempt and counts retries after it.
+	Attempt  int
+	Duration time.Duration
+}
+
+// TokenUsage is the token usage a participant reported for a request.
+type TokenUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// NoopMetrics is a Metrics that discards all measurements.
+type NoopMetrics struct{}
+
+func (NoopMetrics) ObserveRequest(RequestObservation)            {}
+func (NoopMetrics) ObserveRetry(string, string)                  {}
+func (NoopMetrics) ObserveProof(string, ProofStatus)             {}
+func (NoopMetrics) ObserveEpochRefresh(int, error)               {}
+func (NoopMetrics) ObserveTokenUsage(string, string, TokenUsage) {}
+
+type metricsKey struct{}
+
+// contextWithMetrics makes discovery steps run with ctx report to m.
+func contextWithMetrics(ctx context.Context, m Metrics) context.Context {
+	if m == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, metricsKey{}, m)
+}
+
+func metricsFromContext(ctx context.Context) Metrics {
+	if m, ok := ctx.Value(metricsKey{}).(Metrics); ok {
+		return m
+	}
+	return NoopMetrics{}
+}
+
+// errorClass classifies the outcome of an attempt.
+func errorClass(statusCode int, err error) string {
+	var netErr net.Error
+	switch {
+	case err == nil && statusCode < http.StatusBadRequest:
+		return ""
+	case errors.Is(err, ErrSigning), errors.Is(err, ErrNoSigningKey):
+		return ErrorClassSigning
+	case errors.Is(err, ErrClockSkew):
+		return ErrorClassClockSkew
+	case errors.Is(err, ErrInsufficientBalance):
+		return ErrorClassInsufficientBalance
+	case errors.Is(err, ErrCostCeilingExceeded):
+		return ErrorClassCostCeiling
+	case errors.Is(err, context.Canceled):
+		return ErrorClassCanceled
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return ErrorClassTimeout
+	case err != nil:
+		return ErrorClassTransport
+	case statusCode == http.StatusUnauthorized, statusCode == http.StatusForbidden:
+		return ErrorClassUnauthorized
+	case statusCode == http.StatusTooManyRequests:
+		return ErrorClassRateLimited
+	case statusCode >= http.StatusInternalServerError:
+		return ErrorClassServer
+	}
+	return ErrorClassClient
+}
+
+var usageField = []byte(`"usage"`)
+
+// parseUsage finds the last "usage" object in the end of a response body. It
+// covers both complete responses and streams, whose final chunk carries the usage.
+func parseUsage(tail []byte) (TokenUsage, bool) {
+	for end := len(tail); end > 0; {
+		i := bytes.LastIndex(tail[:end], usageField)
+		if i < 0 {
+			break
+		}
+		end = i
+		rest := bytes.TrimLeft(tail[i+len(usageField):], " \t\r\n")
+		if len(rest) == 0 || rest[0] != ':' {
+			continue
+		}
+		var usage *TokenUsage
+		if json.NewDecoder(bytes.NewReader(rest[1:])).Decode(&usage) == nil && usage != nil {
+			return *usage, true
+		}
+	}
+	return TokenUsage{}, false
+}
+
+// measureRoundTrip reports the attempt made by next to s.metrics. Successful
+// attempts are reported once the response body has been read or closed, so
+// their duration and token usage cover streamed responses as well.
+func (s signingRoundTripper) measureRoundTrip(req *http.Request, info *requestInfo, next roundTripStep) (*http.Response, error) {
+	attempt := retryAttempt(req)
+	start := time.Now()
+	resp, err := next(req, info)
+	if attempt > 0 {
+		s.metrics.ObserveRetry(info.endpoint, info.model)
+	}
+	observation := RequestObservation{Endpoint: info.endpoint, Model: info.model, CallerTag: info.callerTag, Attempt: attempt}
+	if err != nil {
+		observation.ErrorClass = errorClass(0, err)
+		observation.Duration = time.Since(start)
+		s.metrics.ObserveRequest(observation)
+		return nil, err
+	}
+
+	observation.StatusCode = resp.StatusCode
+	observation.ErrorClass = errorClass(resp.StatusCode, nil)
+	if resp.StatusCode >= http.StatusBadRequest {
+		// Error responses are reported right away: retried responses are not
+		// always closed by the caller.
+		observation.Duration = time.Since(start)
+		s.metrics.ObserveRequest(observation)
+		return resp, nil
+	}
+	resp.Body = &observedBody{ReadCloser: resp.Body, onDone: func(_, tail []byte) {
+		observation.Duration = time.Since(start)
+		s.metrics.ObserveRequest(observation)
+		if usage, ok := parseUsage(tail); ok {
+			s.metrics.ObserveTokenUsage(info.endpoint, info.model, usage)
+		}
+	}}
+	return resp, nil
+}
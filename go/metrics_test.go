@@ -0,0 +1,106 @@
+package gonkaopenai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/openai/openai-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingMetrics struct {
+	NoopMetrics
+	mu       sync.Mutex
+	requests []RequestObservation
+	retries  int
+	usage    []TokenUsage
+}
+
+func (m *recordingMetrics) ObserveRequest(o RequestObservation) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests = append(m.requests, o)
+}
+
+func (m *recordingMetrics) ObserveRetry(string, string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.retries++
+}
+
+func (m *recordingMetrics) ObserveTokenUsage(_, _ string, usage TokenUsage) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.usage = append(m.usage, usage)
+}
+
+func TestParseUsage(t *testing.T) {
+	usage, ok := parseUsage([]byte(`{"id":"x","choices":[],"usage":{"prompt_tokens":3,"completion_tokens":4,"total_tokens":7}}`))
+	require.True(t, ok)
+	assert.Equal(t, TokenUsage{PromptTokens: 3, CompletionTokens: 4, TotalTokens: 7}, usage)
+
+	stream := "data: {\"choices\":[{\"delta\":{}}],\"usage\":null}\n\n" +
+		"data: {\"choices\":[],\"usage\":{\"prompt_tokens\":5,\"completion_tokens\":6,\"total_tokens\":11}}\n\n" +
+		"data: [DONE]\n\n"
+	usage, ok = parseUsage([]byte(stream))
+	require.True(t, ok)
+	assert.Equal(t, 11, usage.TotalTokens)
+
+	_, ok = parseUsage([]byte(`{"usage":null}`))
+	assert.False(t, ok)
+}
+
+func TestErrorClass(t *testing.T) {
+	assert.Equal(t, "", errorClass(http.StatusOK, nil))
+	assert.Equal(t, ErrorClassUnauthorized, errorClass(http.StatusUnauthorized, nil))
+	assert.Equal(t, ErrorClassRateLimited, errorClass(http.StatusTooManyRequests, nil))
+	assert.Equal(t, ErrorClassServer, errorClass(http.StatusBadGateway, nil))
+	assert.Equal(t, ErrorClassClient, errorClass(http.StatusNotFound, nil))
+	assert.Equal(t, ErrorClassSigning, errorClass(0, &SigningError{Err: ErrInvalidPrivateKey}))
+	assert.Equal(t, ErrorClassCanceled, errorClass(0, context.Canceled))
+}
+
+func TestRequestMetrics(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"chatcmpl-1","object":"chat.completion","model":"m","choices":[],` +
+			`"usage":{"prompt_tokens":3,"completion_tokens":4,"total_tokens":7}}`))
+	}))
+	defer srv.Close()
+
+	metrics := &recordingMetrics{}
+	g, err := NewGonkaOpenAI(Options{
+		GonkaPrivateKey: testPrivateKey,
+		Endpoints:       []Endpoint{{URL: srv.URL + "/v1", Address: testTransferAddress}},
+		Metrics:         metrics,
+	})
+	require.NoError(t, err)
+
+	_, err = g.Chat.Completions.New(context.Background(), openai.ChatCompletionNewParams{
+		Model:    "m",
+		Messages: []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hi")},
+	})
+	require.NoError(t, err)
+
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	require.Len(t, metrics.requests, 2)
+	assert.Equal(t, ErrorClassServer, metrics.requests[0].ErrorClass)
+	assert.Equal(t, srv.URL+"/v1", metrics.requests[0].Endpoint)
+	assert.Equal(t, "m", metrics.requests[0].Model)
+	assert.Equal(t, 1, metrics.requests[1].Attempt)
+	assert.Equal(t, http.StatusOK, metrics.requests[1].StatusCode)
+	assert.Empty(t, metrics.requests[1].ErrorClass)
+	assert.Equal(t, 1, metrics.retries)
+	assert.Equal(t, []TokenUsage{{PromptTokens: 3, CompletionTokens: 4, TotalTokens: 7}}, metrics.usage)
+}
@@ -0,0 +1,186 @@
+package gonkaopenai
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Errors returned by the verification middleware in addition to those of VerifyRequest.
+var (
+	ErrTimestampSkew   = errors.New("request timestamp outside allowed window")
+	ErrReplayedRequest = errors.New("replayed request")
+	// ErrReplayCacheFull means more requests arrived within the timestamp window
+	// than NonceCacheSize allows, so replays of them could not be detected.
+	ErrReplayCacheFull = errors.New("replay cache full")
+)
+
+// Defaults used by VerifyingHandler when the corresponding option is zero.
+const (
+	DefaultMaxTimestampSkew = 60 * time.Second
+	DefaultNonceCacheSize   = 100000
+	DefaultMaxBodyBytes     = 10 << 20
+)
+
+type requesterAddressKey struct{}
+
+// RequesterAddressFromContext returns the authenticated requester address stored by VerifyingHandler.
+func RequesterAddressFromContext(ctx context.Context) (string, bool) {
+	addr, ok := ctx.Value(requesterAddressKey{}).(string)
+	return addr, ok
+}
+
+// MiddlewareOptions configures VerifyingHandler.
+type MiddlewareOptions struct {
+	// TransferAddress is the address of this server that clients sign requests for. Required.
+	TransferAddress string
+	// Resolver optionally resolves requester public keys. If nil, keys are recovered from signatures.
+	Resolver PubKeyResolver
+	// MaxTimestampSkew is how far X-Timestamp may deviate from the server clock.
+	MaxTimestampSkew time.Duration
+	// NonceCacheSize bounds the number of requests remembered for replay protection.
+	// Requests are remembered until their timestamps leave the MaxTimestampSkew
+	// window; requests beyond this many within the window are rejected with
+	// ErrReplayCacheFull.
+	NonceCacheSize int
+	// MaxBodyBytes bounds the request body read to verify the signature, which
+	// happens before the requester is authenticated. Larger bodies are rejected
+	// with an *http.MaxBytesError. Negative disables the limit.
+	MaxBodyBytes int64
+	// OnError writes the response for rejected requests. Defaults to a 401 with
+	// the error text, or a 413 for bodies over MaxBodyBytes.
+	OnError func(w http.ResponseWriter, r *http.Request, err error)
+	// Now overrides the clock, for tests.
+	Now func() time.Time
+}
+
+// VerifyingHandler returns an http.Handler that authenticates Gonka-signed requests
+// before passing them to next.
+//
+// A request is accepted when its signature verifies (see VerifyRequest), its
+// X-Timestamp is within MaxTimestampSkew of the server clock, and the same
+// (address, timestamp, signature) triple has not been seen before. The requester
+// address is available to next via RequesterAddressFromContext.
+func VerifyingHandler(next http.Handler, opts MiddlewareOptions) http.Handler {
+	if opts.MaxTimestampSkew <= 0 {
+		opts.MaxTimestampSkew = DefaultMaxTimestampSkew
+	}
+	if opts.NonceCacheSize <= 0 {
+		opts.NonceCacheSize = DefaultNonceCacheSize
+	}
+	if opts.MaxBodyBytes == 0 {
+		opts.MaxBodyBytes = DefaultMaxBodyBytes
+	}
+	if opts.OnError == nil {
+		opts.OnError = func(w http.ResponseWriter, r *http.Request, err error) {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+		}
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	cache := newNonceCache(opts.NonceCacheSize)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Check the timestamp before the more expensive signature recovery. A
+		// missing or malformed header is reported by VerifyRequest.
+		now := opts.Now().UnixNano()
+		timestamp, err := strconv.ParseInt(r.Header.Get("X-Timestamp"), 10, 64)
+		if err == nil {
+			skew := time.Duration(now - timestamp)
+			if skew > opts.MaxTimestampSkew || skew < -opts.MaxTimestampSkew {
+				opts.OnError(w, r, fmt.Errorf("%w: off by %s", ErrTimestampSkew, skew))
+				return
+			}
+		}
+
+		if opts.MaxBodyBytes > 0 && r.Body != nil {
+			r.Body = http.MaxBytesReader(w, r.Body, opts.MaxBodyBytes)
+		}
+		if err := VerifyRequest(r, opts.Resolver, opts.TransferAddress); err != nil {
+			opts.OnError(w, r, err)
+			return
+		}
+
+		// VerifyRequest has already validated the headers.
+		address := r.Header.Get("X-Requester-Address")
+		key := address + "|" + strconv.FormatInt(timestamp, 10) + "|" + r.Header.Get("Authorization")
+		// The request can be replayed until its timestamp leaves the window.
+		expires := timestamp + int64(opts.MaxTimestampSkew)
+		if err := cache.add(key, expires, now); err != nil {
+			opts.OnError(w, r, err)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), requesterAddressKey{}, address)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// nonceCache remembers requests until their timestamps leave the window, after
+// which the skew check rejects their replays. It holds at most size requests;
+// while it is full of requests still inside the window, new ones are refused
+// rather than forgetting a request that could then be replayed.
+type nonceCache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]struct{}
+	expiry  nonceHeap
+}
+
+type nonceEntry struct {
+	key     string
+	expires int64
+}
+
+// nonceHeap orders entries by expiry, soonest first.
+type nonceHeap []nonceEntry
+
+func (h nonceHeap) Len() int           { return len(h) }
+func (h nonceHeap) Less(i, j int) bool { return h[i].expires < h[j].expires }
+func (h nonceHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *nonceHeap) Push(x any)        { *h = append(*h, x.(nonceEntry)) }
+func (h *nonceHeap) Pop() any {
+	old := *h
+	e := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return e
+}
+
+func newNonceCache(size int) *nonceCache {
+	return &nonceCache{
+		size:    size,
+		entries: make(map[string]struct{}, size),
+	}
+}
+
+// add records key until expires, both in Unix nanoseconds, forgetting requests
+// that expired by now. It returns ErrReplayedRequest if key is already present
+// and ErrReplayCacheFull if there is no room for it.
+func (c *nonceCache) add(key string, expires, now int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for len(c.expiry) > 0 && c.expiry[0].expires < now {
+		delete(c.entries, heap.Pop(&c.expiry).(nonceEntry).key)
+	}
+	if _, ok := c.entries[key]; ok {
+		return ErrReplayedRequest
+	}
+	if len(c.entries) >= c.size {
+		return ErrReplayCacheFull
+	}
+	c.entries[key] = struct{}{}
+	heap.Push(&c.expiry, nonceEntry{key: key, expires: expires})
+	return nil
+}
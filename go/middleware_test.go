@@ -0,0 +1,84 @@
+package gonkaopenai
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyingHandler(t *testing.T) {
+	var gotAddress string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAddress, _ = RequesterAddressFromContext(r.Context())
+	})
+	h := VerifyingHandler(next, MiddlewareOptions{TransferAddress: testTransferAddress})
+
+	req := newSignedRequest(t, `{"model":"m"}`)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, mustAddress(t, testPrivateKey), gotAddress)
+
+	// Sending the exact same request again is a replay.
+	replay := req.Clone(req.Context())
+	replay.Body = io.NopCloser(strings.NewReader(`{"model":"m"}`))
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, replay)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Body.String(), ErrReplayedRequest.Error())
+}
+
+func TestVerifyingHandler_Skew(t *testing.T) {
+	var called bool
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true })
+	h := VerifyingHandler(next, MiddlewareOptions{
+		TransferAddress:  testTransferAddress,
+		MaxTimestampSkew: time.Second,
+		Now:              func() time.Time { return time.Now().Add(time.Minute) },
+	})
+
+	req := newSignedRequest(t, `{}`)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.False(t, called)
+}
+
+func TestNonceCache(t *testing.T) {
+	c := newNonceCache(2)
+	assert.NoError(t, c.add("a", 10, 0))
+	assert.NoError(t, c.add("b", 20, 0))
+	assert.ErrorIs(t, c.add("a", 10, 0), ErrReplayedRequest)
+
+	// Full of requests inside the window: nothing is forgotten
+	assert.ErrorIs(t, c.add("c", 30, 5), ErrReplayCacheFull)
+	assert.ErrorIs(t, c.add("a", 10, 10), ErrReplayedRequest)
+
+	// "a" has left the window and makes room
+	assert.NoError(t, c.add("c", 30, 11))
+	assert.Len(t, c.entries, 2)
+	assert.ErrorIs(t, c.add("b", 20, 11), ErrReplayedRequest)
+}
+
+func TestVerifyingHandler_MaxBodyBytes(t *testing.T) {
+	var called bool
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true })
+	h := VerifyingHandler(next, MiddlewareOptions{TransferAddress: testTransferAddress, MaxBodyBytes: 16})
+
+	req := newSignedRequest(t, `{"model":"a-long-model-name"}`)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	assert.False(t, called)
+
+	req = newSignedRequest(t, `{"model":"m"}`)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.True(t, called)
+}
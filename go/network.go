@@ -0,0 +1,105 @@
+package gonkaopenai
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Network describes a Gonka chain the client talks to.
+type Network struct {
+	// Name is a short identifier such as "testnet" or "mainnet".
+	Name string
+	// ChainID is the Cosmos chain ID.
+	ChainID string
+	// Bech32Prefix is the account address prefix. Derived from ChainID if empty.
+	Bech32Prefix string
+	// ProofStoreKey is the module store that participant proofs must be rooted in.
+	ProofStoreKey string
+	// ParamsKey is the store key of the inference module params, used to prove
+	// the allowed transfer addresses. The inference module is scaffolded with
+	// Ignite, which stores params under "p_" + the module name (ParamsKey in the
+	// module's types/keys.go).
+	ParamsKey string
+	// TransferAgentParamsField is the field number of transfer_agent_access_params
+	// in the inference module's Params message. The allowed transfer addresses
+	// are only verified if it is set.
+	TransferAgentParamsField int
+	// VerifyProof requires proof verification during discovery. It is on for
+	// Mainnet. GONKA_VERIFY_PROOF=1 enables it regardless.
+	VerifyProof bool
+}
+
+// Built-in network profiles.
+var (
+	Testnet = Network{
+		Name:          "testnet",
+		ChainID:       "gonka-testnet-3",
+		Bech32Prefix:  "gonka",
+		ProofStoreKey: "inference",
+		ParamsKey:     "p_inference",
+	}
+	Mainnet = Network{
+		Name:          "mainnet",
+		ChainID:       "gonka-mainnet",
+		Bech32Prefix:  "gonka",
+		ProofStoreKey: "inference",
+		ParamsKey:     "p_inference",
+		VerifyProof:   true,
+	}
+)
+
+// DefaultNetwork is used when neither Options nor GONKA_NETWORK select a network.
+var DefaultNetwork = Testnet
+
+var builtinNetworks = []Network{Testnet, Mainnet}
+
+// NetworkByName returns the built-in network with the given name or chain ID.
+func NetworkByName(name string) (Network, bool) {
+	for _, n := range builtinNetworks {
+		if strings.EqualFold(n.Name, name) || n.ChainID == name {
+			return n, true
+		}
+	}
+	return Network{}, false
+}
+
+// AddressPrefix returns the bech32 account prefix of the network.
+func (n Network) AddressPrefix() string {
+	if n.Bech32Prefix != "" {
+		return n.Bech32Prefix
+	}
+	return strings.Split(n.ChainID, "-")[0]
+}
+
+// Address derives the network's bech32 account address from a private key.
+func (n Network) Address(privateKeyHex string) (string, error) {
+	priv, err := parsePrivateKey(privateKeyHex)
+	if err != nil {
+		return "", err
+	}
+	return pubKeyToAddress(crypto.CompressPubkey(&priv.PublicKey), n.AddressPrefix())
+}
+
+// proofRequired reports whether discovery must verify proofs, either because the
+// network requires it or because GONKA_VERIFY_PROOF=1.
+func (n Network) proofRequired() bool {
+	return n.VerifyProof || os.Getenv("GONKA_VERIFY_PROOF") == "1"
+}
+
+// resolveNetwork returns n if it is set, otherwise the network named by
+// GONKA_NETWORK, otherwise DefaultNetwork.
+func resolveNetwork(n Network) (Network, error) {
+	if n.ChainID != "" {
+		return n, nil
+	}
+	if name := os.Getenv(EnvNetwork); name != "" {
+		if n, ok := NetworkByName(name); ok {
+			return n, nil
+		}
+		return Network{}, fmt.Errorf("unknown network %q in %s", name, EnvNetwork)
+	}
+	return DefaultNetwork, nil
+}
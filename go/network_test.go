@@ -0,0 +1,63 @@
+package gonkaopenai
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNetworkByName(t *testing.T) {
+	n, ok := NetworkByName("mainnet")
+	assert.True(t, ok)
+	assert.Equal(t, Mainnet.ChainID, n.ChainID)
+	assert.True(t, n.proofRequired(), "mainnet discovery is proof-verified")
+
+	n, ok = NetworkByName("gonka-testnet-3")
+	assert.True(t, ok)
+	assert.Equal(t, "testnet", n.Name)
+
+	_, ok = NetworkByName("nope")
+	assert.False(t, ok)
+}
+
+func TestResolveNetwork(t *testing.T) {
+	t.Setenv(EnvNetwork, "")
+	n, err := resolveNetwork(Network{})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultNetwork.ChainID, n.ChainID)
+
+	t.Setenv(EnvNetwork, "mainnet")
+	n, err = resolveNetwork(Network{})
+	require.NoError(t, err)
+	assert.Equal(t, Mainnet.ChainID, n.ChainID)
+
+	custom := Network{ChainID: "custom-1"}
+	n, err = resolveNetwork(custom)
+	require.NoError(t, err)
+	assert.Equal(t, "custom", n.AddressPrefix())
+
+	t.Setenv(EnvNetwork, "unknown")
+	_, err = resolveNetwork(Network{})
+	assert.Error(t, err)
+}
+
+func TestClientsOnDifferentNetworks(t *testing.T) {
+	endpoints := []Endpoint{{URL: "http://participant.test/v1", Address: testTransferAddress}}
+	other := Network{Name: "other", ChainID: "other-1", Bech32Prefix: "other"}
+
+	a, err := NewGonkaOpenAI(Options{GonkaPrivateKey: testPrivateKey, Endpoints: endpoints, Network: Testnet})
+	require.NoError(t, err)
+	_, payload, err := DecodeAddress(testTransferAddress)
+	require.NoError(t, err)
+	otherAddress, err := encodeAddress("other", payload)
+	require.NoError(t, err)
+	otherEndpoints := []Endpoint{{URL: "http://participant.test/v1", Address: otherAddress}}
+	b, err := NewGonkaOpenAI(Options{GonkaPrivateKey: testPrivateKey, Endpoints: otherEndpoints, Network: other})
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(a.GonkaAddress(), "gonka1"))
+	assert.True(t, strings.HasPrefix(b.GonkaAddress(), "other1"))
+	assert.Equal(t, "other-1", b.Network().ChainID)
+}
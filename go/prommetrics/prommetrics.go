@@ -0,0 +1,156 @@
+// Package prommetrics exports the measurements of a Gonka client as Prometheus metrics.
+//
+//	m := prommetrics.New(prometheus.DefaultRegisterer)
+//	client, err := gonkaopenai.NewGonkaOpenAI(gonkaopenai.Options{Metrics: m, ...})
+package prommetrics
+
+import (
+	"strconv"
+
+	gonkaopenai "github.com/gonka-ai/gonka-openai/go"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Namespace prefixes all metric names.
+const Namespace = "gonka"
+
+// OtherCallerTag is the caller label of requests whose caller tag is not in
+// Options.CallerTags.
+const OtherCallerTag = "other"
+
+// Options configures the collectors created by NewWithOptions.
+type Options struct {
+	// CallerTags are the caller tags, set with gonkaopenai.WithCallerTag, that get
+	// a caller label value of their own on requests_total. Requests with any other
+	// tag are counted as OtherCallerTag, so that per-user tags cannot grow the
+	// number of series without bound. By default no tag is labeled.
+	CallerTags []string
+}
+
+// Metrics implements gonkaopenai.Metrics with Prometheus collectors.
+type Metrics struct {
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	retries         *prometheus.CounterVec
+	proofs          *prometheus.CounterVec
+	epochRefreshes  *prometheus.CounterVec
+	participants    prometheus.Gauge
+	tokens          *prometheus.CounterVec
+	callerTags      map[string]bool
+}
+
+var _ gonkaopenai.Metrics = (*Metrics)(nil)
+
+// New creates the collectors and registers them with reg. A nil reg leaves them
+// unregistered; register the returned Metrics, which is a prometheus.Collector, yourself.
+func New(reg prometheus.Registerer) *Metrics {
+	return NewWithOptions(reg, Options{})
+}
+
+// NewWithOptions is New with options.
+func NewWithOptions(reg prometheus.Registerer, opts Options) *Metrics {
+	m := &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "requests_total",
+			Help:      "Request attempts by endpoint, model, status code, error class and allow-listed caller tag.",
+		}, []string{"endpoint", "model", "code", "error_class", "caller"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Name:      "request_duration_seconds",
+			Help:      "Duration of request attempts until the response body was consumed.",
+			Buckets:   []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300},
+		}, []string{"endpoint", "model"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "request_retries_total",
+			Help:      "Retried request attempts by endpoint and model.",
+		}, []string{"endpoint", "model"}),
+		proofs: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "proof_verifications_total",
+			Help:      "Outcomes of proof-verified discovery steps.",
+		}, []string{"kind", "status"}),
+		epochRefreshes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "epoch_refreshes_total",
+			Help:      "Fetches of the participants of an epoch, by result.",
+		}, []string{"result"}),
+		participants: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "participants",
+			Help:      "Number of participants returned by the last successful epoch refresh.",
+		}),
+		tokens: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "tokens_total",
+			Help:      "Tokens reported in responses by endpoint, model and type (prompt or completion).",
+		}, []string{"endpoint", "model", "type"}),
+		callerTags: make(map[string]bool, len(opts.CallerTags)),
+	}
+	for _, tag := range opts.CallerTags {
+		m.callerTags[tag] = true
+	}
+	if reg != nil {
+		reg.MustRegister(m)
+	}
+	return m
+}
+
+func (m *Metrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{m.requests, m.requestDuration, m.retries, m.proofs, m.epochRefreshes, m.participants, m.tokens}
+}
+
+// Describe implements prometheus.Collector.
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	for _, c := range m.collectors() {
+		c.Describe(ch)
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	for _, c := range m.collectors() {
+		c.Collect(ch)
+	}
+}
+
+// ObserveRequest implements gonkaopenai.Metrics.
+func (m *Metrics) ObserveRequest(o gonkaopenai.RequestObservation) {
+	code := ""
+	if o.StatusCode != 0 {
+		code = strconv.Itoa(o.StatusCode)
+	}
+	caller := o.CallerTag
+	if caller != "" && !m.callerTags[caller] {
+		caller = OtherCallerTag
+	}
+	m.requests.WithLabelValues(o.Endpoint, o.Model, code, o.ErrorClass, caller).Inc()
+	m.requestDuration.WithLabelValues(o.Endpoint, o.Model).Observe(o.Duration.Seconds())
+}
+
+// ObserveRetry implements gonkaopenai.Metrics.
+func (m *Metrics) ObserveRetry(endpoint, model string) {
+	m.retries.WithLabelValues(endpoint, model).Inc()
+}
+
+// ObserveProof implements gonkaopenai.Metrics.
+func (m *Metrics) ObserveProof(kind string, status gonkaopenai.ProofStatus) {
+	m.proofs.WithLabelValues(kind, status.String()).Inc()
+}
+
+// ObserveEpochRefresh implements gonkaopenai.Metrics.
+func (m *Metrics) ObserveEpochRefresh(participants int, err error) {
+	if err != nil {
+		m.epochRefreshes.WithLabelValues("error").Inc()
+		return
+	}
+	m.epochRefreshes.WithLabelValues("ok").Inc()
+	m.participants.Set(float64(participants))
+}
+
+// ObserveTokenUsage implements gonkaopenai.Metrics.
+func (m *Metrics) ObserveTokenUsage(endpoint, model string, usage gonkaopenai.TokenUsage) {
+	m.tokens.WithLabelValues(endpoint, model, "prompt").Add(float64(usage.PromptTokens))
+	m.tokens.WithLabelValues(endpoint, model, "completion").Add(float64(usage.CompletionTokens))
+}
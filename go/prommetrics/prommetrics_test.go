@@ -0,0 +1,42 @@
+package prommetrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	gonkaopenai "github.com/gonka-ai/gonka-openai/go"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewWithOptions(reg, Options{CallerTags: []string{"search"}})
+
+	m.ObserveRequest(gonkaopenai.RequestObservation{Endpoint: "e", Model: "m", StatusCode: 200, Duration: time.Second, CallerTag: "search"})
+	m.ObserveRequest(gonkaopenai.RequestObservation{Endpoint: "e", Model: "m", ErrorClass: gonkaopenai.ErrorClassTransport})
+	m.ObserveRequest(gonkaopenai.RequestObservation{Endpoint: "e", Model: "m", StatusCode: 200, CallerTag: "user-1234"})
+	m.ObserveRetry("e", "m")
+	m.ObserveProof(gonkaopenai.ProofKindParticipants, gonkaopenai.ProofVerified)
+	m.ObserveEpochRefresh(7, nil)
+	m.ObserveEpochRefresh(0, errors.New("down"))
+	m.ObserveTokenUsage("e", "m", gonkaopenai.TokenUsage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15})
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.requests.WithLabelValues("e", "m", "200", "", "search")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.requests.WithLabelValues("e", "m", "", "transport", "")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.requests.WithLabelValues("e", "m", "200", "", OtherCallerTag)),
+		"tags that are not allow-listed share a label value")
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.retries.WithLabelValues("e", "m")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.proofs.WithLabelValues("participants", "verified")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.epochRefreshes.WithLabelValues("error")))
+	assert.Equal(t, 7.0, testutil.ToFloat64(m.participants))
+	assert.Equal(t, 10.0, testutil.ToFloat64(m.tokens.WithLabelValues("e", "m", "prompt")))
+	assert.Equal(t, 5.0, testutil.ToFloat64(m.tokens.WithLabelValues("e", "m", "completion")))
+
+	count, err := testutil.GatherAndCount(reg, "gonka_request_duration_seconds")
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
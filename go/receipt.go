@@ -0,0 +1,208 @@
+package gonkaopenai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gonka-ai/gonka-openai/go/chainclient"
+)
+
+// InferenceReceipt records how a request was signed and sent and what the
+// participant returned, so that it can later be matched with the chain.
+type InferenceReceipt struct {
+	// InferenceID identifies the inference: the X-Inference-Id response header if
+	// present, otherwise the response's "id", otherwise the request signature.
+	InferenceID string
+	// ResponseID is the "id" field of the response body, if any.
+	ResponseID string
+	// Signature is the Authorization header the request was signed with.
+	Signature        string
+	Timestamp        int64
+	TransferAddress  string
+	RequesterAddress string
+	// Endpoint is the full URL the request was sent to.
+	Endpoint string
+	Model    string
+	// CallerTag is the tag set with WithCallerTag, if any.
+	CallerTag  string
+	StatusCode int
+	// Header holds the response headers.
+	Header     http.Header
+	ReceivedAt time.Time
+
+	// billed is set once LookupInference has recorded the billed cost.
+	billed bool
+}
+
+type receiptKey struct{}
+
+// CaptureReceipt returns a context that makes the signing transport fill in the
+// returned receipt for the request made with it. The receipt is complete once
+// the call has returned (for streams, once the stream has been closed). If the
+// request is retried, the receipt describes the last attempt.
+func CaptureReceipt(ctx context.Context) (context.Context, *InferenceReceipt) {
+	r := &InferenceReceipt{}
+	return context.WithValue(ctx, receiptKey{}, r), r
+}
+
+// maxObservedHead bounds how much of a response body is kept to find its id.
+const maxObservedHead = 4096
+
+// maxObservedTail bounds how much of the end of a response body is kept to find
+// its token usage, which comes last, also in streams.
+const maxObservedTail = 4096
+
+// newReceipt builds the receipt for a signed request and its response.
+func newReceipt(req *http.Request, resp *http.Response, payload string, timestamp int64, transferAddress, requester string) InferenceReceipt {
+	tag, _ := CallerTagFromContext(req.Context())
+	return InferenceReceipt{
+		Signature:        req.Header.Get("Authorization"),
+		Timestamp:        timestamp,
+		TransferAddress:  transferAddress,
+		RequesterAddress: requester,
+		Endpoint:         req.URL.String(),
+		Model:            requestModel(payload),
+		CallerTag:        tag,
+		StatusCode:       resp.StatusCode,
+		Header:           resp.Header.Clone(),
+		ReceivedAt:       time.Now(),
+	}
+}
+
+// requestModel returns the "model" field of a request body, if any.
+func requestModel(payload string) string {
+	var body struct {
+		Model string `json:"model"`
+	}
+	_ = json.Unmarshal([]byte(payload), &body)
+	return body.Model
+}
+
+// complete fills in the identifiers found in the start of the response body.
+func (r *InferenceReceipt) complete(head []byte) {
+	r.ResponseID = responseID(head)
+	switch {
+	case r.Header.Get("X-Inference-Id") != "":
+		r.InferenceID = r.Header.Get("X-Inference-Id")
+	case r.ResponseID != "":
+		r.InferenceID = r.ResponseID
+	default:
+		r.InferenceID = r.Signature
+	}
+}
+
+// responseID returns the top-level "id" of a JSON response body, or of the first
+// chunk of a stream of server-sent events, from the start of the body.
+func responseID(head []byte) string {
+	if !bytes.HasPrefix(bytes.TrimSpace(head), []byte("{")) {
+		var chunk []byte
+		scanner := bufio.NewScanner(bytes.NewReader(head))
+		for scanner.Scan() {
+			if data, ok := bytes.CutPrefix(scanner.Bytes(), []byte("data:")); ok {
+				chunk = data
+				break
+			}
+		}
+		head = chunk
+	}
+	dec := json.NewDecoder(bytes.NewReader(head))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return ""
+	}
+	// The body may be cut short, so read fields until "id" rather than decoding it whole
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return ""
+		}
+		if key == "id" {
+			var id string
+			if err := dec.Decode(&id); err != nil {
+				return ""
+			}
+			return id
+		}
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return ""
+		}
+	}
+	return ""
+}
+
+// observedBody wraps a response body, keeping its first and last bytes, and
+// calls onDone once when the body has been read to the end or closed.
+type observedBody struct {
+	io.ReadCloser
+	head   []byte
+	tail   []byte
+	once   sync.Once
+	onDone func(head, tail []byte)
+}
+
+func (b *observedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if room := maxObservedHead - len(b.head); room > 0 {
+		b.head = append(b.head, p[:min(n, room)]...)
+	}
+	b.tail = append(b.tail, p[:n]...)
+	if len(b.tail) > 2*maxObservedTail {
+		b.tail = append([]byte(nil), b.tail[len(b.tail)-maxObservedTail:]...)
+	}
+	if err == io.EOF {
+		b.finish()
+	}
+	return n, err
+}
+
+func (b *observedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.finish()
+	return err
+}
+
+func (b *observedBody) finish() {
+	b.once.Do(func() {
+		tail := b.tail
+		if len(tail) > maxObservedTail {
+			tail = tail[len(tail)-maxObservedTail:]
+		}
+		b.onDone(b.head, tail)
+	})
+}
+
+// LookupInference looks up the inference described by receipt on chain, so that
+// its recording and billed cost (Inference.ActualCost) can be confirmed.
+// Participants index inferences by the request signature, so it is looked up
+// first, then InferenceID. It returns an error matching chainclient.ErrNotFound
+// if it has not been recorded yet. The billed cost is recorded, once per
+// receipt, as spend of the requester's key for KeyPolicyLeastSpent.
+func (g *GonkaOpenAI) LookupInference(ctx context.Context, receipt *InferenceReceipt) (*chainclient.Inference, error) {
+	if receipt == nil || (receipt.InferenceID == "" && receipt.Signature == "") {
+		return nil, fmt.Errorf("receipt has no inference id")
+	}
+	id := receipt.Signature
+	if id == "" {
+		id = receipt.InferenceID
+	}
+	inf, err := g.chain.Inference(ctx, id)
+	if errors.Is(err, chainclient.ErrNotFound) && receipt.InferenceID != "" && id != receipt.InferenceID {
+		inf, err = g.chain.Inference(ctx, receipt.InferenceID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if g.keys != nil && !receipt.billed && inf.ActualCost > 0 {
+		g.keys.RecordSpend(receipt.RequesterAddress, uint64(inf.ActualCost))
+		receipt.billed = true
+	}
+	return inf, nil
+}
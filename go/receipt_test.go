@@ -0,0 +1,76 @@
+package gonkaopenai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/openai/openai-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInferenceReceipt(t *testing.T) {
+	var signature string
+	var lookups []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/chain-api/productscience/inference/inference/inference/"):
+			lookups = append(lookups, strings.TrimPrefix(r.URL.Path, "/chain-api/productscience/inference/inference/inference/"))
+			w.Write([]byte(`{"inference":{"inference_id":"chatcmpl-1","actual_cost":"1500","model":"m"}}`))
+		default:
+			signature = r.Header.Get("Authorization")
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"id":"chatcmpl-1","object":"chat.completion","model":"m","choices":[]}`))
+		}
+	}))
+	defer srv.Close()
+
+	var fromCallback InferenceReceipt
+	g, err := NewGonkaOpenAI(Options{
+		GonkaPrivateKey: testPrivateKey,
+		Endpoints:       []Endpoint{{URL: srv.URL + "/v1", Address: testTransferAddress}},
+		OnReceipt:       func(r InferenceReceipt) { fromCallback = r },
+	})
+	require.NoError(t, err)
+
+	ctx, receipt := CaptureReceipt(context.Background())
+	_, err = g.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
+		Model:    "m",
+		Messages: []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hi")},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "chatcmpl-1", receipt.InferenceID)
+	assert.Equal(t, "chatcmpl-1", receipt.ResponseID)
+	assert.Equal(t, signature, receipt.Signature)
+	assert.Equal(t, testTransferAddress, receipt.TransferAddress)
+	assert.Equal(t, g.GonkaAddress(), receipt.RequesterAddress)
+	assert.Equal(t, "m", receipt.Model)
+	assert.Equal(t, http.StatusOK, receipt.StatusCode)
+	assert.Equal(t, srv.URL+"/v1/chat/completions", receipt.Endpoint)
+	assert.NotZero(t, receipt.Timestamp)
+	assert.Equal(t, *receipt, fromCallback)
+
+	inf, err := g.LookupInference(context.Background(), receipt)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1500, inf.ActualCost)
+	assert.Equal(t, []string{signature}, lookups, "inferences are looked up by signature")
+	assert.Equal(t, uint64(1500), g.Keys().Spent(g.GonkaAddress()))
+
+	// Looking the same receipt up again does not bill it twice
+	_, err = g.LookupInference(context.Background(), receipt)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1500), g.Keys().Spent(g.GonkaAddress()))
+}
+
+func TestResponseID(t *testing.T) {
+	assert.Equal(t, "chatcmpl-1", responseID([]byte(`{"object":"chat.completion","choices":[{"message":`+
+		`{"tool_calls":[{"id":"call_1"}]}}],"id":"chatcmpl-1"}`)))
+	assert.Empty(t, responseID([]byte(`{"choices":[{"message":{"tool_calls":[{"id":"call_1"}]}}]}`)))
+	assert.Equal(t, "chatcmpl-2", responseID([]byte("data: {\"id\":\"chatcmpl-2\",\"choices\":[]}\n\ndata: {\"id\":\"x\"")))
+	// Cut short before the id
+	assert.Empty(t, responseID([]byte(`{"choices":[{"message":{"content":"hel`)))
+}
@@ -0,0 +1,178 @@
+package gonkaopenai
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Status is a report of a client's state, for diagnostics.
+type Status struct {
+	Network string `json:"network"`
+	// SourceURL is the node the participants were fetched from. It is empty
+	// when endpoints were configured explicitly, as are the discovery fields.
+	SourceURL   string `json:"source_url,omitempty"`
+	EpochID     uint64 `json:"epoch_id,omitempty"`
+	BlockHeight int64  `json:"block_height,omitempty"`
+	// ParticipantsVerified reports whether the participants were checked against a proof.
+	ParticipantsVerified bool `json:"participants_verified"`
+	// AllowedAddresses is the ProofStatus of the allowed transfer addresses the
+	// participants were filtered by, if they were.
+	AllowedAddresses string `json:"allowed_addresses,omitempty"`
+	// RefreshedAt is when the participants were last fetched.
+	RefreshedAt time.Time `json:"refreshed_at"`
+
+	RequesterAddress string `json:"requester_address"`
+	// Requesters are the addresses of all signing keys, see Keys.
+	Requesters []string `json:"requesters,omitempty"`
+	// ClockSkew is how far the participants' clocks are ahead of the local clock,
+	// valid if ClockSkewMeasured.
+	ClockSkew         time.Duration `json:"clock_skew_ns"`
+	ClockSkewMeasured bool          `json:"clock_skew_measured"`
+
+	// BaseURL is the endpoint requests are sent to.
+	BaseURL   string           `json:"base_url"`
+	Endpoints []EndpointStatus `json:"endpoints"`
+}
+
+// EndpointStatus describes an endpoint and the attempts sent to it.
+type EndpointStatus struct {
+	URL     string `json:"url"`
+	Address string `json:"address"`
+	// Healthy is false if the last attempt failed: no response was received or
+	// the participant answered with a server error.
+	Healthy             bool      `json:"healthy"`
+	Requests            int64     `json:"requests"`
+	Failures            int64     `json:"failures"`
+	ConsecutiveFailures int64     `json:"consecutive_failures"`
+	LastStatusCode      int       `json:"last_status_code,omitempty"`
+	LastError           string    `json:"last_error,omitempty"`
+	LastSuccess         time.Time `json:"last_success"`
+	LastFailure         time.Time `json:"last_failure"`
+	// Latencies are until the response headers arrived.
+	LastLatency time.Duration `json:"last_latency_ns"`
+	MeanLatency time.Duration `json:"mean_latency_ns"`
+}
+
+// Status reports the client's current state.
+func (g *GonkaOpenAI) Status() Status {
+	offset, measured := g.ClockSkew()
+	st := Status{
+		Network:           g.network.Name,
+		SourceURL:         g.sourceURL,
+		RefreshedAt:       g.refreshedAt,
+		RequesterAddress:  g.GonkaAddress(),
+		ClockSkew:         offset,
+		ClockSkewMeasured: measured,
+		BaseURL:           g.baseURL,
+		Endpoints:         make([]EndpointStatus, 0, len(g.endpoints)),
+	}
+	if g.participants != nil {
+		st.EpochID = g.participants.EpochID
+		st.BlockHeight = g.participants.BlockHeight
+		st.ParticipantsVerified = g.participants.Verified
+	}
+	if g.sourceURL != "" {
+		st.AllowedAddresses = g.allowed.Status.String()
+	}
+	if g.keys != nil {
+		st.Requesters = g.keys.Addresses()
+	}
+	for _, ep := range g.endpoints {
+		st.Endpoints = append(st.Endpoints, g.stats.status(ep))
+	}
+	return st
+}
+
+// StatusHandler returns a handler that serves Status as JSON, for example on an
+// admin port.
+func (g *GonkaOpenAI) StatusHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(g.Status())
+	})
+}
+
+// endpointStats tracks the attempts sent to each endpoint. A nil
+// *endpointStats records nothing.
+type endpointStats struct {
+	mu        sync.Mutex
+	endpoints map[string]*endpointStat
+}
+
+type endpointStat struct {
+	requests, failures, consecutiveFailures int64
+	lastStatusCode                          int
+	lastError                               string
+	lastSuccess, lastFailure                time.Time
+	lastLatency, totalLatency               time.Duration
+}
+
+func newEndpointStats() *endpointStats {
+	return &endpointStats{endpoints: make(map[string]*endpointStat)}
+}
+
+// record adds an attempt sent to endpoint, which failed if err is set or the
+// participant answered with a server error.
+func (s *endpointStats) record(endpoint string, statusCode int, err error, latency time.Duration) {
+	if s == nil || endpoint == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.endpoints[endpoint]
+	if !ok {
+		st = &endpointStat{}
+		s.endpoints[endpoint] = st
+	}
+	st.requests++
+	st.lastStatusCode = statusCode
+	st.lastLatency = latency
+	st.totalLatency += latency
+	now := time.Now()
+	switch {
+	case err != nil:
+		st.lastError = err.Error()
+	case statusCode >= http.StatusInternalServerError:
+		st.lastError = http.StatusText(statusCode)
+	default:
+		st.consecutiveFailures = 0
+		st.lastSuccess = now
+		return
+	}
+	st.failures++
+	st.consecutiveFailures++
+	st.lastFailure = now
+}
+
+func (s *endpointStats) status(ep Endpoint) EndpointStatus {
+	status := EndpointStatus{URL: ep.URL, Address: ep.Address, Healthy: true}
+	if s == nil {
+		return status
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.endpoints[ep.URL]
+	if !ok {
+		return status
+	}
+	status.Healthy = st.consecutiveFailures == 0
+	status.Requests = st.requests
+	status.Failures = st.failures
+	status.ConsecutiveFailures = st.consecutiveFailures
+	status.LastStatusCode = st.lastStatusCode
+	status.LastError = st.lastError
+	status.LastSuccess = st.lastSuccess
+	status.LastFailure = st.lastFailure
+	status.LastLatency = st.lastLatency
+	status.MeanLatency = st.totalLatency / time.Duration(st.requests)
+	return status
+}
@@ -0,0 +1,103 @@
+package gonkaopenai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openai/openai-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatus(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"chatcmpl-1","object":"chat.completion","model":"m","choices":[]}`))
+	}))
+	defer srv.Close()
+
+	endpoint := Endpoint{URL: srv.URL + "/v1", Address: testTransferAddress}
+	g, err := NewGonkaOpenAI(Options{
+		GonkaPrivateKey: testPrivateKey,
+		Endpoints:       []Endpoint{endpoint},
+	})
+	require.NoError(t, err)
+
+	_, err = g.Chat.Completions.New(context.Background(), openai.ChatCompletionNewParams{
+		Model:    "m",
+		Messages: []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hi")},
+	})
+	require.NoError(t, err)
+
+	st := g.Status()
+	assert.Equal(t, Testnet.Name, st.Network)
+	assert.Empty(t, st.SourceURL)
+	assert.Empty(t, st.AllowedAddresses)
+	assert.Equal(t, g.GonkaAddress(), st.RequesterAddress)
+	assert.Equal(t, []string{g.GonkaAddress()}, st.Requesters)
+	assert.Equal(t, endpoint.URL, st.BaseURL)
+	require.Len(t, st.Endpoints, 1)
+	ep := st.Endpoints[0]
+	assert.Equal(t, endpoint.Address, ep.Address)
+	assert.True(t, ep.Healthy)
+	assert.Equal(t, int64(2), ep.Requests)
+	assert.Equal(t, int64(1), ep.Failures)
+	assert.Zero(t, ep.ConsecutiveFailures)
+	assert.Equal(t, http.StatusOK, ep.LastStatusCode)
+	assert.Equal(t, http.StatusText(http.StatusBadGateway), ep.LastError)
+	assert.False(t, ep.LastFailure.IsZero())
+	assert.Positive(t, ep.MeanLatency)
+
+	rec := httptest.NewRecorder()
+	g.StatusHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	var served Status
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &served))
+	assert.Equal(t, st.RequesterAddress, served.RequesterAddress)
+	assert.Equal(t, int64(2), served.Endpoints[0].Requests)
+
+	rec = httptest.NewRecorder()
+	g.StatusHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/status", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestParticipantsBlockHeight(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"block":{"header":{"height":"42"}},"active_participants":{"epoch_id":7,` +
+			`"participants":[{"index":"` + testTransferAddress + `","inference_url":"http://a.test"}]}}`))
+	}))
+	defer srv.Close()
+
+	set, err := getParticipantsWithProof(context.Background(), srv.URL, "current", Testnet)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), set.BlockHeight)
+	assert.Equal(t, uint64(7), set.EpochID)
+	assert.False(t, set.Verified)
+}
+
+func TestParseParticipants(t *testing.T) {
+	raw := []byte(`{"active_participants":{"epoch_id":3,"participants":[` +
+		`{"index":"` + testTransferAddress + `","inference_url":"http://a.test","weight":2},` +
+		`{"index":"gonka1excluded","inference_url":"http://b.test"}]},` +
+		`"excluded_participants":[{"address":"gonka1excluded"}]}`)
+
+	p, err := ParseParticipants(raw, Testnet)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), p.EpochID)
+	assert.Len(t, p.Participants, 2)
+	assert.True(t, p.Excluded["gonka1excluded"])
+	assert.Equal(t, []Endpoint{{URL: "http://a.test/v1", Address: testTransferAddress}}, p.Endpoints())
+	assert.Equal(t, raw, p.Raw)
+
+	_, err = ParseParticipants([]byte(`not json`), Testnet)
+	assert.Error(t, err)
+}
@@ -0,0 +1,60 @@
+package gonkaopenai
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// TimestampSource returns the nanosecond timestamp placed in X-Timestamp and signed with each request.
+type TimestampSource func() int64
+
+// Base values for hybrid timestamp generation, captured once per process.
+var (
+	wallBase     = time.Now()
+	lastHybridNs atomic.Int64
+)
+
+// HybridTimestampNs returns a timestamp in nanoseconds that combines the wall clock
+// with the monotonic clock, like hybrid_timestamp_ns in the Python client.
+//
+// The wall clock is read once at startup and advanced with the monotonic clock, so
+// timestamps stay close to real time but never go backwards when the system clock is
+// adjusted. Concurrent callers always receive distinct, strictly increasing values.
+func HybridTimestampNs() int64 {
+	ts := wallBase.UnixNano() + time.Since(wallBase).Nanoseconds()
+	for {
+		last := lastHybridNs.Load()
+		if ts <= last {
+			ts = last + 1
+		}
+		if lastHybridNs.CompareAndSwap(last, ts) {
+			return ts
+		}
+	}
+}
+
+var timestampSource atomic.Pointer[TimestampSource]
+
+// SetTimestampSource replaces the process-wide timestamp source used by all signing
+// transports and returns the previous one. Passing nil restores HybridTimestampNs.
+// It is mainly intended for tests.
+func SetTimestampSource(src TimestampSource) TimestampSource {
+	var prev *TimestampSource
+	if src == nil {
+		prev = timestampSource.Swap(nil)
+	} else {
+		prev = timestampSource.Swap(&src)
+	}
+	if prev == nil {
+		return HybridTimestampNs
+	}
+	return *prev
+}
+
+// signingTimestamp returns the next timestamp from the configured source.
+func signingTimestamp() int64 {
+	if src := timestampSource.Load(); src != nil {
+		return (*src)()
+	}
+	return HybridTimestampNs()
+}
@@ -0,0 +1,48 @@
+package gonkaopenai
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHybridTimestampNs_UniqueAcrossGoroutines(t *testing.T) {
+	const workers, perWorker = 8, 1000
+	results := make([][]int64, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				results[w] = append(results[w], HybridTimestampNs())
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	seen := make(map[int64]bool, workers*perWorker)
+	for _, r := range results {
+		for i, ts := range r {
+			assert.False(t, seen[ts], "duplicate timestamp %d", ts)
+			seen[ts] = true
+			if i > 0 {
+				assert.Greater(t, ts, r[i-1])
+			}
+		}
+	}
+
+	// Timestamps stay close to wall clock time.
+	assert.InDelta(t, time.Now().UnixNano(), HybridTimestampNs(), float64(time.Second))
+}
+
+func TestSetTimestampSource(t *testing.T) {
+	prev := SetTimestampSource(func() int64 { return 42 })
+	defer SetTimestampSource(prev)
+
+	req := newSignedRequest(t, `{}`)
+	assert.Equal(t, strconv.Itoa(42), req.Header.Get("X-Timestamp"))
+}
@@ -0,0 +1,103 @@
+package gonkaopenai
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName names the tracer spans are created with.
+const instrumentationName = "github.com/gonka-ai/gonka-openai/go"
+
+// Span attribute keys for the signed request.
+const (
+	attrEndpoint         = attribute.Key("gonka.endpoint")
+	attrTransferAddress  = attribute.Key("gonka.transfer_address")
+	attrRequesterAddress = attribute.Key("gonka.requester_address")
+	attrModel            = attribute.Key("gen_ai.request.model")
+	attrCallerTag        = attribute.Key("gonka.caller_tag")
+)
+
+// tracerFromContext returns a tracer of the provider of the span in ctx, so that
+// discovery spans follow the provider the caller traces with, or the global one.
+func tracerFromContext(ctx context.Context) trace.Tracer {
+	if span := trace.SpanFromContext(ctx); span.SpanContext().IsValid() {
+		return span.TracerProvider().Tracer(instrumentationName)
+	}
+	return otel.Tracer(instrumentationName)
+}
+
+// startSpan starts an internal span named name as a child of the span in ctx.
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracerFromContext(ctx).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan records err, if any, and ends span.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// traceRoundTrip wraps the round trip made by next in a client span and
+// propagates the trace context to the participant. For successful responses the
+// span of a recording tracer ends once the body has been read or closed, so it
+// covers streamed responses as well.
+func (s signingRoundTripper) traceRoundTrip(req *http.Request, info *requestInfo, next roundTripStep) (*http.Response, error) {
+	ctx, span := s.tracer.Start(req.Context(), "gonka.request",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", req.Method),
+			attribute.String("url.full", req.URL.String()),
+			attribute.String("server.address", req.URL.Hostname()),
+			attribute.Int("http.request.resend_count", retryAttempt(req)),
+		))
+	req = req.WithContext(ctx)
+	if s.propagator != nil {
+		s.propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+	}
+
+	resp, err := next(req, info)
+	span.SetAttributes(
+		attrEndpoint.String(info.endpoint),
+		attrTransferAddress.String(info.transferAddress),
+		attrRequesterAddress.String(info.requesterAddress),
+		attrModel.String(info.model),
+	)
+	if info.callerTag != "" {
+		span.SetAttributes(attrCallerTag.String(info.callerTag))
+	}
+	if err != nil {
+		endSpan(span, err)
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode >= http.StatusBadRequest {
+		// Retried error responses are not always closed by the caller
+		span.SetStatus(codes.Error, resp.Status)
+		span.End()
+		return resp, nil
+	}
+	if !span.IsRecording() {
+		// Nothing would see the usage or the end time, so leave the body alone
+		span.End()
+		return resp, nil
+	}
+	resp.Body = &observedBody{ReadCloser: resp.Body, onDone: func(_, tail []byte) {
+		if usage, ok := parseUsage(tail); ok {
+			span.SetAttributes(
+				attribute.Int("gen_ai.usage.input_tokens", usage.PromptTokens),
+				attribute.Int("gen_ai.usage.output_tokens", usage.CompletionTokens),
+			)
+		}
+		span.End()
+	}}
+	return resp, nil
+}
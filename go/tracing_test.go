@@ -0,0 +1,97 @@
+package gonkaopenai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openai/openai-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func spanAttributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func TestRequestSpan(t *testing.T) {
+	var traceparent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"chatcmpl-1","object":"chat.completion","model":"m","choices":[]}`))
+	}))
+	defer srv.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	g, err := NewGonkaOpenAI(Options{
+		GonkaPrivateKey: testPrivateKey,
+		Endpoints:       []Endpoint{{URL: srv.URL + "/v1", Address: testTransferAddress}},
+		TracerProvider:  provider,
+	})
+	require.NoError(t, err)
+
+	_, err = g.Chat.Completions.New(context.Background(), openai.ChatCompletionNewParams{
+		Model:    "m",
+		Messages: []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hi")},
+	})
+	require.NoError(t, err)
+
+	var request sdktrace.ReadOnlySpan
+	for _, s := range recorder.Ended() {
+		if s.Name() == "gonka.request" {
+			request = s
+		}
+	}
+	require.NotNil(t, request, "request span must be ended")
+	attrs := spanAttributes(request)
+	assert.Equal(t, srv.URL+"/v1", attrs[attrEndpoint].AsString())
+	assert.Equal(t, testTransferAddress, attrs[attrTransferAddress].AsString())
+	assert.Equal(t, g.GonkaAddress(), attrs[attrRequesterAddress].AsString())
+	assert.Equal(t, "m", attrs[attrModel].AsString())
+	assert.Equal(t, int64(0), attrs["http.request.resend_count"].AsInt64())
+	assert.Equal(t, int64(http.StatusOK), attrs["http.response.status_code"].AsInt64())
+	assert.Contains(t, traceparent, request.SpanContext().TraceID().String())
+}
+
+func TestDiscoverySpansFollowParent(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "parent")
+	_, err := FetchNodeIdentity(ctx, srv.URL)
+	require.Error(t, err)
+	parent.End()
+
+	ended := recorder.Ended()
+	require.Len(t, ended, 2)
+	assert.Equal(t, "gonka.FetchNodeIdentity", ended[0].Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), ended[0].Parent().SpanID())
+	assert.Equal(t, "Error", ended[0].Status().Code.String())
+}
+
+func TestRequestSpanNotRecording(t *testing.T) {
+	body := http.NoBody
+	s := signingRoundTripper{tracer: noop.NewTracerProvider().Tracer(instrumentationName)}
+	req, err := http.NewRequest(http.MethodPost, "http://participant.test/v1/chat/completions", nil)
+	require.NoError(t, err)
+	resp, err := s.traceRoundTrip(req, &requestInfo{}, func(*http.Request, *requestInfo) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: body}, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, body, resp.Body, "bodies are not observed for spans nothing records")
+}
@@ -0,0 +1,129 @@
+package gonkaopenai
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// UsageKey identifies what token usage is aggregated by.
+type UsageKey struct {
+	// Participant is the transfer address of the participant that served the requests.
+	Participant string `json:"participant"`
+	Model       string `json:"model"`
+	// CallerTag is the tag set with WithCallerTag, if any.
+	CallerTag string `json:"caller_tag,omitempty"`
+}
+
+// UsageTotals are aggregated token usage.
+type UsageTotals struct {
+	// Requests counts the responses the usage was taken from.
+	Requests         int64 `json:"requests"`
+	PromptTokens     int64 `json:"prompt_tokens"`
+	CompletionTokens int64 `json:"completion_tokens"`
+	TotalTokens      int64 `json:"total_tokens"`
+}
+
+func (t *UsageTotals) add(o UsageTotals) {
+	t.Requests += o.Requests
+	t.PromptTokens += o.PromptTokens
+	t.CompletionTokens += o.CompletionTokens
+	t.TotalTokens += o.TotalTokens
+}
+
+// UsageRecord is the usage aggregated under one key.
+type UsageRecord struct {
+	UsageKey
+	UsageTotals
+}
+
+// UsageSnapshot is a copy of a ledger's records, for export.
+type UsageSnapshot struct {
+	// Since is when the ledger was created or last reset.
+	Since time.Time `json:"since"`
+	Taken time.Time `json:"taken"`
+	// Records are sorted by participant, model and caller tag.
+	Records []UsageRecord `json:"records"`
+}
+
+// UsageLedger aggregates the token usage participants report in responses, per
+// participant, model and caller tag. It is safe for concurrent use.
+type UsageLedger struct {
+	mu      sync.Mutex
+	since   time.Time
+	records map[UsageKey]*UsageTotals
+}
+
+// NewUsageLedger returns an empty ledger.
+func NewUsageLedger() *UsageLedger {
+	return &UsageLedger{since: time.Now(), records: make(map[UsageKey]*UsageTotals)}
+}
+
+// Record adds the usage of one response under key.
+func (l *UsageLedger) Record(key UsageKey, usage TokenUsage) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	totals, ok := l.records[key]
+	if !ok {
+		totals = &UsageTotals{}
+		l.records[key] = totals
+	}
+	totals.add(UsageTotals{
+		Requests:         1,
+		PromptTokens:     int64(usage.PromptTokens),
+		CompletionTokens: int64(usage.CompletionTokens),
+		TotalTokens:      int64(usage.TotalTokens),
+	})
+}
+
+// Totals returns the usage recorded under keys matching filter, in which empty
+// fields match any value. The zero UsageKey sums all usage.
+func (l *UsageLedger) Totals(filter UsageKey) UsageTotals {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var sum UsageTotals
+	for key, totals := range l.records {
+		if (filter.Participant == "" || filter.Participant == key.Participant) &&
+			(filter.Model == "" || filter.Model == key.Model) &&
+			(filter.CallerTag == "" || filter.CallerTag == key.CallerTag) {
+			sum.add(*totals)
+		}
+	}
+	return sum
+}
+
+// Snapshot returns a copy of the recorded usage.
+func (l *UsageLedger) Snapshot() UsageSnapshot {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.snapshot()
+}
+
+// Reset clears the ledger and returns the usage recorded until then, so that
+// periodic exports neither miss nor double count usage.
+func (l *UsageLedger) Reset() UsageSnapshot {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	snapshot := l.snapshot()
+	l.since = snapshot.Taken
+	l.records = make(map[UsageKey]*UsageTotals)
+	return snapshot
+}
+
+func (l *UsageLedger) snapshot() UsageSnapshot {
+	records := make([]UsageRecord, 0, len(l.records))
+	for key, totals := range l.records {
+		records = append(records, UsageRecord{UsageKey: key, UsageTotals: *totals})
+	}
+	sort.Slice(records, func(i, j int) bool {
+		a, b := records[i].UsageKey, records[j].UsageKey
+		if a.Participant != b.Participant {
+			return a.Participant < b.Participant
+		}
+		if a.Model != b.Model {
+			return a.Model < b.Model
+		}
+		return a.CallerTag < b.CallerTag
+	})
+	return UsageSnapshot{Since: l.since, Taken: time.Now(), Records: records}
+}
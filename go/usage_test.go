@@ -0,0 +1,72 @@
+package gonkaopenai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openai/openai-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageLedger(t *testing.T) {
+	ledger := NewUsageLedger()
+	ledger.Record(UsageKey{Participant: "p1", Model: "m", CallerTag: "search"}, TokenUsage{PromptTokens: 3, CompletionTokens: 4, TotalTokens: 7})
+	ledger.Record(UsageKey{Participant: "p1", Model: "m", CallerTag: "search"}, TokenUsage{PromptTokens: 1, CompletionTokens: 1, TotalTokens: 2})
+	ledger.Record(UsageKey{Participant: "p2", Model: "m"}, TokenUsage{PromptTokens: 5, TotalTokens: 5})
+
+	assert.Equal(t, UsageTotals{Requests: 3, PromptTokens: 9, CompletionTokens: 5, TotalTokens: 14}, ledger.Totals(UsageKey{}))
+	assert.Equal(t, UsageTotals{Requests: 2, PromptTokens: 4, CompletionTokens: 5, TotalTokens: 9}, ledger.Totals(UsageKey{CallerTag: "search"}))
+	assert.Equal(t, int64(5), ledger.Totals(UsageKey{Participant: "p2"}).TotalTokens)
+
+	snapshot := ledger.Reset()
+	require.Len(t, snapshot.Records, 2)
+	assert.Equal(t, "p1", snapshot.Records[0].Participant)
+	assert.Equal(t, int64(2), snapshot.Records[0].Requests)
+	assert.Empty(t, ledger.Snapshot().Records)
+	assert.Equal(t, snapshot.Taken, ledger.Snapshot().Since)
+}
+
+func TestUsageRecordedFromResponses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/embeddings" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"object":"list","data":[],"model":"e","usage":{"prompt_tokens":2,"total_tokens":2}}`))
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("data: {\"id\":\"c1\",\"object\":\"chat.completion.chunk\",\"model\":\"m\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"hi\"}}]}\n\n" +
+			"data: {\"id\":\"c1\",\"object\":\"chat.completion.chunk\",\"model\":\"m\",\"choices\":[],\"usage\":{\"prompt_tokens\":3,\"completion_tokens\":4,\"total_tokens\":7}}\n\n" +
+			"data: [DONE]\n\n"))
+	}))
+	defer srv.Close()
+
+	g, err := NewGonkaOpenAI(Options{
+		GonkaPrivateKey: testPrivateKey,
+		Endpoints:       []Endpoint{{URL: srv.URL + "/v1", Address: testTransferAddress}},
+	})
+	require.NoError(t, err)
+
+	ctx := WithCallerTag(context.Background(), "search")
+	stream := g.Chat.Completions.NewStreaming(ctx, openai.ChatCompletionNewParams{
+		Model:    "m",
+		Messages: []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hi")},
+	})
+	for stream.Next() {
+	}
+	require.NoError(t, stream.Err())
+	require.NoError(t, stream.Close())
+
+	_, err = g.Embeddings.New(context.Background(), openai.EmbeddingNewParams{
+		Model: "e",
+		Input: openai.EmbeddingNewParamsInputUnion{OfString: openai.String("hi")},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []UsageRecord{
+		{UsageKey{Participant: testTransferAddress, Model: "e"}, UsageTotals{Requests: 1, PromptTokens: 2, TotalTokens: 2}},
+		{UsageKey{Participant: testTransferAddress, Model: "m", CallerTag: "search"}, UsageTotals{Requests: 1, PromptTokens: 3, CompletionTokens: 4, TotalTokens: 7}},
+	}, g.Usage().Snapshot().Records)
+}
@@ -1,7 +1,6 @@
 package gonkaopenai
 
 import (
-	"bytes"
 	"context"
 	"crypto/ecdsa"
 	crand "crypto/rand"
@@ -9,65 +8,64 @@ import (
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"math/big"
 	"math/rand"
 	"net/http"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/btcsuite/btcd/btcutil/bech32"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gonka-ai/gonka-openai/go/chainclient"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/crypto/ripemd160" //nolint:SA1019 // RIPEMD-160 is required for Cosmos address generation, standard despite deprecation.
 )
 
 // FetchAllowedTransferAddresses fetches the allowed transfer addresses via the node's /chain-api/ proxy.
-func FetchAllowedTransferAddresses(ctx context.Context, nodeUrl string) (map[string]bool, error) {
-	base := strings.TrimRight(nodeUrl, "/")
-	if strings.HasSuffix(base, "/v1") {
-		base = base[:len(base)-3]
-	}
-	url := base + "/chain-api/productscience/inference/inference/params"
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return nil, err
-	}
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("chain params request failed with status %d", resp.StatusCode)
-	}
-	body, err := io.ReadAll(resp.Body)
+func FetchAllowedTransferAddresses(ctx context.Context, nodeUrl string) (_ map[string]bool, err error) {
+	ctx, span := startSpan(ctx, "gonka.FetchAllowedTransferAddresses", attribute.String("gonka.source_url", nodeUrl))
+	defer func() { endSpan(span, err) }()
+	params, err := chainclient.New(nodeUrl, chainclient.Options{}).Params(ctx)
 	if err != nil {
 		return nil, err
 	}
-	var result struct {
-		Params struct {
-			TransferAgentAccessParams struct {
-				AllowedTransferAddresses []string `json:"allowed_transfer_addresses"`
-			} `json:"transfer_agent_access_params"`
-		} `json:"params"`
-	}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, err
-	}
-	set := make(map[string]bool, len(result.Params.TransferAgentAccessParams.AllowedTransferAddresses))
-	for _, a := range result.Params.TransferAgentAccessParams.AllowedTransferAddresses {
+	set := make(map[string]bool, len(params.TransferAgentAccessParams.AllowedTransferAddresses))
+	for _, a := range params.TransferAgentAccessParams.AllowedTransferAddresses {
 		set[a] = true
 	}
 	return set, nil
 }
 
 // FetchNodeIdentity fetches the node identity including delegate_ta, returning endpoints.
-func FetchNodeIdentity(ctx context.Context, nodeUrl string) ([]Endpoint, error) {
+// The identity is not verified; see FetchVerifiedNodeIdentity.
+func FetchNodeIdentity(ctx context.Context, nodeUrl string) (_ []Endpoint, err error) {
+	ctx, span := startSpan(ctx, "gonka.FetchNodeIdentity", attribute.String("gonka.node_url", nodeUrl))
+	defer func() { endSpan(span, err) }()
+	identity, err := fetchNodeIdentity(ctx, nodeUrl)
+	if err != nil {
+		return nil, err
+	}
+	return identity.delegateEndpoints()
+}
+
+// nodeIdentity is the response of a node's /v1/identity call.
+type nodeIdentity struct {
+	// Data is kept raw so its signature can be checked against the exact bytes.
+	Data      json.RawMessage `json:"data"`
+	Signature string          `json:"signature"`
+}
+
+func fetchNodeIdentity(ctx context.Context, nodeUrl string) (*nodeIdentity, error) {
 	base := strings.TrimRight(nodeUrl, "/")
 	if strings.HasSuffix(base, "/v1") {
 		base = base[:len(base)-3]
@@ -91,16 +89,28 @@ func FetchNodeIdentity(ctx context.Context, nodeUrl string) ([]Endpoint, error)
 	if err != nil {
 		return nil, err
 	}
-	var result struct {
-		Data struct {
-			DelegateTA map[string]string `json:"delegate_ta"`
-		} `json:"data"`
+	var identity nodeIdentity
+	if err := json.Unmarshal(body, &identity); err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (n *nodeIdentity) delegateEndpoints() ([]Endpoint, error) {
+	if len(n.Data) == 0 {
+		return nil, nil
+	}
+	var data struct {
+		DelegateTA map[string]string `json:"delegate_ta"`
 	}
-	if err := json.Unmarshal(body, &result); err != nil {
+	if err := json.Unmarshal(n.Data, &data); err != nil {
 		return nil, err
 	}
 	var endpoints []Endpoint
-	for u, addr := range result.Data.DelegateTA {
+	for u, addr := range data.DelegateTA {
+		if _, _, err := DecodeAddress(addr); err != nil {
+			return nil, fmt.Errorf("identity delegate_ta for %s: %w", u, err)
+		}
 		endpoints = append(endpoints, Endpoint{
 			URL:     ensureV1(u),
 			Address: addr,
@@ -141,6 +151,7 @@ func GonkaBaseURL(endpoints []Endpoint) string {
 }
 
 // GetEndpointsFromEnv parses endpoints from GONKA_ENDPOINTS env var in the format "url;address, url;address".
+// Entries that are malformed or have an invalid address are skipped; use ParseEndpoints to get an error instead.
 func GetEndpointsFromEnv() []Endpoint {
 	env := os.Getenv(EnvEndpoints)
 	if env == "" {
@@ -148,33 +159,87 @@ func GetEndpointsFromEnv() []Endpoint {
 	}
 	var out []Endpoint
 	for _, part := range strings.Split(env, ",") {
-		p := strings.TrimSpace(part)
-		if p == "" {
-			continue
+		ep, err := parseEndpoint(part)
+		if err == nil && ep.URL != "" {
+			out = append(out, ep)
 		}
-		segs := strings.SplitN(p, ";", 2)
-		if len(segs) != 2 {
-			continue
+	}
+	return out
+}
+
+// ParseEndpoints parses endpoints in the GONKA_ENDPOINTS format "url;address, url;address",
+// returning an error for malformed entries or invalid addresses.
+func ParseEndpoints(value string) ([]Endpoint, error) {
+	var out []Endpoint
+	for _, part := range strings.Split(value, ",") {
+		ep, err := parseEndpoint(part)
+		if err != nil {
+			return nil, err
 		}
-		url := strings.TrimSpace(segs[0])
-		addr := strings.TrimSpace(segs[1])
-		if url != "" && addr != "" {
-			out = append(out, Endpoint{URL: url, Address: addr})
+		if ep.URL != "" {
+			out = append(out, ep)
 		}
 	}
-	return out
+	return out, nil
 }
 
-// GonkaSignature signs request body with ECDSA secp256k1 and returns base64.
-func GonkaSignature(body []byte, privateKeyHex string) (string, error) {
-	if strings.HasPrefix(privateKeyHex, "0x") {
-		privateKeyHex = privateKeyHex[2:]
+// parseEndpoint parses a single "url;address" entry. Blank entries yield a zero Endpoint.
+func parseEndpoint(part string) (Endpoint, error) {
+	p := strings.TrimSpace(part)
+	if p == "" {
+		return Endpoint{}, nil
+	}
+	segs := strings.SplitN(p, ";", 2)
+	if len(segs) != 2 {
+		return Endpoint{}, fmt.Errorf("malformed endpoint %q, expected url;address", p)
 	}
+	url := strings.TrimSpace(segs[0])
+	addr := strings.TrimSpace(segs[1])
+	if url == "" || addr == "" {
+		return Endpoint{}, fmt.Errorf("malformed endpoint %q, expected url;address", p)
+	}
+	if _, _, err := DecodeAddress(addr); err != nil {
+		return Endpoint{}, fmt.Errorf("endpoint %s: %w", url, err)
+	}
+	return Endpoint{URL: url, Address: addr}, nil
+}
+
+// ErrInvalidPrivateKey is returned when a private key is not a valid hex-encoded secp256k1 key.
+var ErrInvalidPrivateKey = errors.New("invalid private key")
+
+// ErrSigning is matched by every *SigningError.
+var ErrSigning = errors.New("failed to sign request")
+
+// SigningError is returned by the signing transport when a request could not be signed.
+// Such requests are never sent.
+type SigningError struct {
+	URL string
+	Err error
+}
+
+func (e *SigningError) Error() string {
+	return fmt.Sprintf("%v %s: %v", ErrSigning, e.URL, e.Err)
+}
+
+func (e *SigningError) Unwrap() []error { return []error{ErrSigning, e.Err} }
+
+// parsePrivateKey decodes a hex-encoded secp256k1 private key, with or without 0x prefix.
+func parsePrivateKey(privateKeyHex string) (*ecdsa.PrivateKey, error) {
+	privateKeyHex = strings.TrimPrefix(privateKeyHex, "0x")
 	keyBytes, err := hex.DecodeString(privateKeyHex)
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPrivateKey, err)
 	}
 	priv, err := crypto.ToECDSA(keyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPrivateKey, err)
+	}
+	return priv, nil
+}
+
+// GonkaSignature signs request body with ECDSA secp256k1 and returns base64.
+func GonkaSignature(body []byte, privateKeyHex string) (string, error) {
+	priv, err := parsePrivateKey(privateKeyHex)
 	if err != nil {
 		return "", err
 	}
@@ -188,34 +253,25 @@ func GonkaSignature(body []byte, privateKeyHex string) (string, error) {
 	if s.Cmp(new(big.Int).Rsh(curveOrder, 1)) == 1 {
 		s = new(big.Int).Sub(curveOrder, s)
 	}
-	sigBytes := append(r.Bytes(), s.Bytes()...)
+	// r and s are encoded as fixed-width 32-byte big-endian integers
+	sigBytes := make([]byte, 64)
+	r.FillBytes(sigBytes[:32])
+	s.FillBytes(sigBytes[32:])
 	return base64.StdEncoding.EncodeToString(sigBytes), nil
 }
 
-// GonkaAddress derives a Cosmos bech32 address from private key.
+// GonkaAddress derives a Cosmos bech32 address from private key for DefaultNetwork.
+// Use Network.Address for other networks.
 func GonkaAddress(privateKeyHex string) (string, error) {
-	if strings.HasPrefix(privateKeyHex, "0x") {
-		privateKeyHex = privateKeyHex[2:]
-	}
-	keyBytes, err := hex.DecodeString(privateKeyHex)
-	if err != nil {
-		return "", err
-	}
-	priv, err := crypto.ToECDSA(keyBytes)
-	if err != nil {
-		return "", err
-	}
-	pub := crypto.CompressPubkey(&priv.PublicKey)
+	return DefaultNetwork.Address(privateKeyHex)
+}
+
+// pubKeyToAddress derives a bech32 address with the given prefix from a compressed public key.
+func pubKeyToAddress(pub []byte, prefix string) (string, error) {
 	sha := sha256.Sum256(pub)
 	hasher := ripemd160.New()
 	hasher.Write(sha[:])
-	ripe := hasher.Sum(nil)
-	five, err := bech32.ConvertBits(ripe[:], 8, 5, true)
-	if err != nil {
-		return "", err
-	}
-	prefix := strings.Split(GonkaChainID, "-")[0]
-	return bech32.Encode(prefix, five)
+	return encodeAddress(prefix, hasher.Sum(nil))
 }
 
 // SignatureComponents contains the components needed for signature generation
@@ -247,14 +303,106 @@ func SignComponentsWithKey(components SignatureComponents, privateKeyHex string)
 
 type signingRoundTripper struct {
 	rt         http.RoundTripper
-	privateKey string
-	address    string
 	endpoints  []Endpoint
+	keys       *KeyRing // picks the key to sign each request with
+	skew       *clockSkew
+	checks     *requestChecks
+	onReceipt  func(InferenceReceipt)
+	tracer     trace.Tracer // traces requests if set
+	propagator propagation.TextMapPropagator
+	metrics    Metrics
+	logger     *slog.Logger
+	hooks      *hooks
+	usage      *UsageLedger // records the token usage of responses if set
+	stats      *endpointStats
+	debug      *debugDumper // dumps requests and responses if set
+	// forwardCallerTag sets the OpenAI "user" field of requests to their caller tag
+	forwardCallerTag bool
+}
+
+// requestChecks are run by the signing transport before a request is signed.
+// A check that returns an error stops the request from being sent.
+type requestChecks struct {
+	mu     sync.RWMutex
+	checks []*requestCheck
+}
+
+type requestCheck struct {
+	fn func(*http.Request) error
+}
+
+// add adds check and returns a function that removes it again.
+func (c *requestChecks) add(check func(*http.Request) error) (remove func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry := &requestCheck{fn: check}
+	c.checks = append(c.checks, entry)
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.checks = slices.DeleteFunc(c.checks, func(e *requestCheck) bool { return e == entry })
+	}
+}
+
+func (c *requestChecks) run(req *http.Request) error {
+	if c == nil {
+		return nil
+	}
+	// Checks may query the chain, so they run without holding the lock
+	c.mu.RLock()
+	checks := slices.Clone(c.checks)
+	c.mu.RUnlock()
+	for _, check := range checks {
+		if err := check.fn(req); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (s signingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
-	// Generate timestamp in nanoseconds
-	timestamp := time.Now().UnixNano()
+	next := s.roundTrip
+	if s.metrics != nil {
+		next = func(req *http.Request, info *requestInfo) (*http.Response, error) {
+			return s.measureRoundTrip(req, info, s.roundTrip)
+		}
+	}
+	if s.tracer != nil {
+		return s.traceRoundTrip(req, &requestInfo{}, next)
+	}
+	return next(req, &requestInfo{})
+}
+
+// roundTripStep is a stage of a signed round trip; instrumentation wraps
+// roundTrip in further steps.
+type roundTripStep func(req *http.Request, info *requestInfo) (*http.Response, error)
+
+// requestInfo describes how a request was routed and signed, for instrumentation.
+type requestInfo struct {
+	endpoint         string
+	transferAddress  string
+	requesterAddress string
+	model            string
+	attempt          int
+	callerTag        string
+}
+
+// roundTrip signs and sends req, filling in info as far as it gets.
+func (s signingRoundTripper) roundTrip(req *http.Request, info *requestInfo) (*http.Response, error) {
+	logger := s.logger
+	if logger == nil {
+		logger = discardLogger
+	}
+	info.callerTag, _ = CallerTagFromContext(req.Context())
+	if info.callerTag != "" {
+		logger = logger.With("caller", info.callerTag)
+	}
+
+	// Refuse to sign if a client-level check rejects the request
+	if err := s.checks.run(req); err != nil {
+		logger.Warn("request refused", "url", req.URL.String(), errAttr(err))
+		return nil, err
+	}
 
 	// Determine the appropriate transfer address for this request
 	var transferAddress string
@@ -267,54 +415,145 @@ func (s signingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error
 		for _, endpoint := range s.endpoints {
 			if strings.HasPrefix(endpoint.URL, baseURL) {
 				transferAddress = endpoint.Address
+				info.endpoint = endpoint.URL
+				info.transferAddress = endpoint.Address
 				break
 			}
 		}
 
 		// If no matching endpoint found, we can't proceed
 		if transferAddress == "" {
+			logger.Error("no transfer address for endpoint", "url", baseURL)
 			return nil, fmt.Errorf("no transfer address found for endpoint: %s", baseURL)
 		}
 	} else {
 		return nil, fmt.Errorf("request URL is nil")
 	}
 
+	// Generate a unique, monotonically increasing timestamp in nanoseconds,
+	// corrected by the clock offset measured from the participant
+	timestamp := s.skew.apply(info.endpoint, signingTimestamp())
+
 	var payload string
 
 	if req.Body != nil {
 		data, err := io.ReadAll(req.Body)
-		if err == nil {
-			payload = string(data)
-			components := SignatureComponents{
-				Payload:         payload,
-				Timestamp:       timestamp,
-				TransferAddress: transferAddress,
-			}
-
-			sig, err := SignComponentsWithKey(components, s.privateKey)
-			if err == nil {
-				req.Header.Set("Authorization", sig)
-			}
+		req.Body.Close()
+		if err != nil {
+			err = &SigningError{URL: req.URL.String(), Err: fmt.Errorf("failed to read request body: %w", err)}
+			logger.Error("request signing failed", "url", req.URL.String(), errAttr(err))
+			return nil, err
 		}
-		req.Body = io.NopCloser(bytes.NewReader(data))
-	} else {
-		components := SignatureComponents{
-			Payload:         "",
-			Timestamp:       timestamp,
-			TransferAddress: transferAddress,
+		payload = string(data)
+		if s.forwardCallerTag {
+			payload = withUserField(req.URL.Path, payload, info.callerTag)
+			req.ContentLength = int64(len(payload))
 		}
+		req.Body = io.NopCloser(strings.NewReader(payload))
+	}
+	info.model = requestModel(payload)
+	info.attempt = retryAttempt(req)
+	if info.attempt > 0 {
+		s.hooks.retry(RetryEvent{URL: req.URL.String(), Endpoint: info.endpoint, Model: info.model, CallerTag: info.callerTag, Attempt: info.attempt})
+	}
 
-		sig, err := SignComponentsWithKey(components, s.privateKey)
-		if err == nil {
-			req.Header.Set("Authorization", sig)
-		}
+	privateKey, address, err := s.keys.pick(req.Context())
+	if err != nil {
+		logger.Error("request signing failed", "url", req.URL.String(), errAttr(err))
+		return nil, &SigningError{URL: req.URL.String(), Err: err}
 	}
+	info.requesterAddress = address
+
+	components := SignatureComponents{
+		Payload:         payload,
+		Timestamp:       timestamp,
+		TransferAddress: transferAddress,
+	}
+	sig, err := SignComponentsWithKey(components, privateKey)
+	if err != nil {
+		logger.Error("request signing failed", "url", req.URL.String(), "requester_address", address, errAttr(err))
+		return nil, &SigningError{URL: req.URL.String(), Err: err}
+	}
+	req.Header.Set("Authorization", sig)
 
 	// Set headers
-	req.Header.Set("X-Requester-Address", s.address)
+	req.Header.Set("X-Requester-Address", address)
 	req.Header.Set("X-Timestamp", strconv.FormatInt(timestamp, 10))
+	logger.Debug("request signed", "url", req.URL.String(), "transfer_address", transferAddress,
+		"requester_address", address, "model", info.model, "timestamp", timestamp)
+	s.debug.request(req, components, address, info.attempt)
+	s.hooks.requestSigned(RequestSignedEvent{
+		URL:              req.URL.String(),
+		Endpoint:         info.endpoint,
+		TransferAddress:  transferAddress,
+		RequesterAddress: address,
+		Model:            info.model,
+		CallerTag:        info.callerTag,
+		Timestamp:        timestamp,
+		Attempt:          info.attempt,
+	})
+
+	start := time.Now()
+	resp, err := s.rt.RoundTrip(req)
+	response := ResponseEvent{
+		URL:              req.URL.String(),
+		Endpoint:         info.endpoint,
+		RequesterAddress: address,
+		Model:            info.model,
+		CallerTag:        info.callerTag,
+		Attempt:          info.attempt,
+		Err:              err,
+		Duration:         time.Since(start),
+	}
+	if err != nil {
+		s.hooks.response(response)
+		s.stats.record(info.endpoint, 0, err, response.Duration)
+		s.debug.failure(req, err, response.Duration)
+		return nil, err
+	}
+	response.StatusCode = resp.StatusCode
+	s.hooks.response(response)
+	s.stats.record(info.endpoint, resp.StatusCode, nil, response.Duration)
+	if s.debug != nil {
+		resp.Body = &observedBody{ReadCloser: resp.Body, onDone: func(head, _ []byte) {
+			s.debug.response(req, resp, head, response.Duration)
+		}}
+	}
+
+	// Track the participant's clock and explain rejections caused by skew
+	if _, ok := s.skew.observe(info.endpoint, start, time.Now(), resp.Header.Get("Date")); ok &&
+		(resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden) {
+		if skewErr := s.skew.check(info.endpoint); skewErr != nil {
+			logger.Warn("request rejected due to clock skew", "url", req.URL.String(), "status", resp.StatusCode, errAttr(skewErr))
+			resp.Body.Close()
+			return nil, skewErr
+		}
+	}
 
-	return s.rt.RoundTrip(req)
+	// Record a receipt and the token usage once the response body has been consumed
+	capture, _ := req.Context().Value(receiptKey{}).(*InferenceReceipt)
+	ledger := s.usage
+	if resp.StatusCode >= http.StatusBadRequest {
+		ledger = nil
+	}
+	if capture != nil || s.onReceipt != nil || ledger != nil {
+		receipt := newReceipt(req, resp, payload, timestamp, transferAddress, address)
+		resp.Body = &observedBody{ReadCloser: resp.Body, onDone: func(head, tail []byte) {
+			if ledger != nil {
+				if usage, ok := parseUsage(tail); ok {
+					ledger.Record(UsageKey{Participant: transferAddress, Model: info.model, CallerTag: info.callerTag}, usage)
+				}
+			}
+			receipt.complete(head)
+			if capture != nil {
+				*capture = receipt
+			}
+			if s.onReceipt != nil {
+				s.onReceipt(receipt)
+			}
+		}}
+	}
+	return resp, nil
 }
 
 type HTTPClientOptions struct {
@@ -323,29 +562,92 @@ type HTTPClientOptions struct {
 	Endpoints  []Endpoint
 	Client     *http.Client
 	SourceUrl  string // URL to fetch endpoints from using GetParticipantsWithProof
+	// Network selects the chain profile used for address derivation and proof
+	// verification. Defaults to GONKA_NETWORK or DefaultNetwork.
+	Network Network
+	// AllowAddressMismatch permits an Address that differs from the address of
+	// PrivateKey. Use it for delegated or authz setups where the requester
+	// account is not the signing key's own account.
+	AllowAddressMismatch bool
+	// ClockSkewTolerance is the largest clock offset, measured from participants'
+	// Date headers, that is corrected automatically. Larger offsets fail with
+	// ErrClockSkew. Defaults to DefaultClockSkewTolerance; negative disables correction.
+	ClockSkewTolerance time.Duration
+	// OnReceipt, if set, is called with the receipt of every signed request once
+	// its response body has been read or closed.
+	OnReceipt func(InferenceReceipt)
+	// Keys, if set, are signed with instead of PrivateKey and Address, picked per
+	// request by KeyPolicy. Each key's requester address is checked like Address.
+	Keys      []SigningKey
+	KeyPolicy KeyPolicy
+	// TracerProvider traces each request with an OpenTelemetry span. Defaults to
+	// the global provider, which does nothing unless one has been installed.
+	TracerProvider trace.TracerProvider
+	// Propagator injects the trace context into requests to participants.
+	// Defaults to W3C Trace Context.
+	Propagator propagation.TextMapPropagator
+	// Metrics, if set, receives request, retry and token usage measurements, and
+	// the discovery measurements when SourceUrl is used.
+	Metrics Metrics
+	// Logger, if set, receives discovery and signing logs, with key material
+	// redacted. The client logs nothing by default.
+	Logger *slog.Logger
+	// Hooks are called on request events, and on discovery events when SourceUrl is used.
+	Hooks Hooks
+	// Usage, if set, records the token usage reported in responses, including
+	// the final chunk of streams, per participant, model and caller tag.
+	Usage *UsageLedger
+	// Debug, or GONKA_DEBUG=1, dumps each signed request, with the components
+	// its signature covers, and its response to DebugWriter, which defaults to
+	// os.Stderr. The Authorization header is truncated. Dumps include bodies, so
+	// do not enable it where prompts must not be recorded.
+	Debug       bool
+	DebugWriter io.Writer
+	// ForwardCallerTag sets the OpenAI "user" field of chat completion,
+	// completion and embedding requests to the caller tag set with
+	// WithCallerTag, unless the request sets it already.
+	ForwardCallerTag bool
 }
 
 // GonkaHTTPClient creates an HTTP client that signs requests with the private key.
 func GonkaHTTPClient(opts HTTPClientOptions) (*http.Client, error) {
+	return gonkaHTTPClient(opts, newHooks(opts.Hooks))
+}
+
+// gonkaHTTPClient creates the client of GonkaHTTPClient, reporting events to
+// hooks, which NewGonkaOpenAI shares with its own discovery.
+func gonkaHTTPClient(opts HTTPClientOptions, hooks *hooks) (*http.Client, error) {
+	keys := opts.Keys
+	if len(keys) == 0 {
+		if _, err := parsePrivateKey(opts.PrivateKey); err != nil {
+			return nil, err
+		}
+		keys = []SigningKey{{PrivateKey: opts.PrivateKey, Address: opts.Address}}
+	}
+	network, err := resolveNetwork(opts.Network)
+	if err != nil {
+		return nil, err
+	}
 	if opts.Client == nil {
 		opts.Client = &http.Client{}
 	}
-	if opts.Address == "" {
-		addr, err := GonkaAddress(opts.PrivateKey)
-		if err == nil {
-			opts.Address = addr
-		}
+	ring, err := newKeyRing(network, opts.KeyPolicy, opts.AllowAddressMismatch, keys)
+	if err != nil {
+		return nil, err
 	}
 
 	// Get endpoints from SourceUrl if provided
 	endpoints := opts.Endpoints
 	if opts.SourceUrl != "" {
 		// SourceUrl takes precedence over Endpoints
-		var err error
-		endpoints, err = GetParticipantsWithProof(context.Background(), opts.SourceUrl, "current")
+		ctx := contextWithMetrics(context.Background(), opts.Metrics)
+		ctx = contextWithLogger(ctx, newLogger(opts.Logger))
+		ctx = contextWithHooks(ctx, hooks)
+		participants, err := getParticipantsWithProof(ctx, opts.SourceUrl, "current", network)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get participants with proof: %w", err)
 		}
+		endpoints = participants.Endpoints()
 
 		// Ensure we got at least one endpoint
 		if len(endpoints) == 0 {
@@ -369,11 +671,31 @@ func GonkaHTTPClient(opts HTTPClientOptions) (*http.Client, error) {
 	if rt == nil {
 		rt = http.DefaultTransport
 	}
+	tracerProvider := opts.TracerProvider
+	if tracerProvider == nil {
+		tracerProvider = otel.GetTracerProvider()
+	}
+	propagator := opts.Propagator
+	if propagator == nil {
+		propagator = propagation.TraceContext{}
+	}
 	opts.Client.Transport = signingRoundTripper{
 		rt:         rt,
-		privateKey: opts.PrivateKey,
-		address:    opts.Address,
 		endpoints:  endpoints,
+		keys:       ring,
+		skew:       newClockSkew(opts.ClockSkewTolerance),
+		checks:     &requestChecks{},
+		onReceipt:  opts.OnReceipt,
+		tracer:     tracerProvider.Tracer(instrumentationName),
+		propagator: propagator,
+		metrics:    opts.Metrics,
+		logger:     newLogger(opts.Logger),
+		hooks:      hooks,
+		usage:      opts.Usage,
+		stats:      newEndpointStats(),
+		debug:      newDebugDumper(opts.Debug, opts.DebugWriter),
+
+		forwardCallerTag: opts.ForwardCallerTag,
 	}
 	return opts.Client, nil
 }
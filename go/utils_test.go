@@ -0,0 +1,74 @@
+package gonkaopenai
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) { return 0, errors.New("read failed") }
+
+func TestInvalidPrivateKeyRejectedAtConstruction(t *testing.T) {
+	endpoints := []Endpoint{{URL: "http://participant.test/v1", Address: testTransferAddress}}
+
+	_, err := GonkaHTTPClient(HTTPClientOptions{PrivateKey: "not-hex", Endpoints: endpoints})
+	assert.ErrorIs(t, err, ErrInvalidPrivateKey)
+
+	_, err = NewGonkaOpenAI(Options{GonkaPrivateKey: "abcd", Endpoints: endpoints})
+	assert.ErrorIs(t, err, ErrInvalidPrivateKey)
+}
+
+func TestRoundTripSigningErrors(t *testing.T) {
+	var sent bool
+	rt := signingRoundTripper{
+		rt: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			sent = true
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+		}),
+		keys:      testKeyRing(t, testPrivateKey),
+		endpoints: []Endpoint{{URL: "http://participant.test/v1", Address: testTransferAddress}},
+	}
+
+	req, err := http.NewRequest(http.MethodPost, "http://participant.test/v1/chat/completions", failingReader{})
+	require.NoError(t, err)
+	_, err = rt.RoundTrip(req)
+	assert.ErrorIs(t, err, ErrSigning)
+	var signingErr *SigningError
+	assert.ErrorAs(t, err, &signingErr)
+
+	rt.keys = testKeyRing(t, "zz")
+	req, err = http.NewRequest(http.MethodGet, "http://participant.test/v1/models", nil)
+	require.NoError(t, err)
+	_, err = rt.RoundTrip(req)
+	assert.ErrorIs(t, err, ErrSigning)
+	assert.ErrorIs(t, err, ErrInvalidPrivateKey)
+	assert.False(t, sent, "unsigned requests must not be sent")
+}
+
+func TestRequestChecksRunUnlocked(t *testing.T) {
+	checks := &requestChecks{}
+	started, release := make(chan struct{}), make(chan struct{})
+	remove := checks.add(func(*http.Request) error {
+		close(started)
+		<-release
+		return nil
+	})
+	req, err := http.NewRequest(http.MethodPost, "http://participant.test/v1/chat/completions", nil)
+	require.NoError(t, err)
+	done := make(chan error)
+	go func() { done <- checks.run(req) }()
+	<-started
+
+	// A slow check, such as a chain query, does not block adding or removing checks
+	removeOther := checks.add(func(*http.Request) error { return errors.New("refused") })
+	removeOther()
+	remove()
+	close(release)
+	assert.NoError(t, <-done)
+	assert.Empty(t, checks.checks)
+}
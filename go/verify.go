@@ -0,0 +1,131 @@
+package gonkaopenai
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Errors returned by VerifyRequest. They are wrapped with additional context,
+// so use errors.Is to check for them.
+var (
+	ErrMissingSignature        = errors.New("missing Authorization header")
+	ErrMissingTimestamp        = errors.New("missing X-Timestamp header")
+	ErrMissingRequesterAddress = errors.New("missing X-Requester-Address header")
+	ErrInvalidTimestamp        = errors.New("invalid X-Timestamp header")
+	ErrMalformedSignature      = errors.New("malformed signature")
+	ErrInvalidSignature        = errors.New("invalid signature")
+	ErrAddressMismatch         = errors.New("signer does not match requester address")
+)
+
+// PubKeyResolver returns the compressed secp256k1 public key registered for a requester address.
+type PubKeyResolver func(ctx context.Context, address string) ([]byte, error)
+
+// VerifyRequest verifies a request signed by a Gonka client for the given transfer address.
+//
+// It rebuilds the signed payload from the request body, the X-Timestamp header and
+// transferAddress, and checks the signature in the Authorization header. When resolver
+// is nil the public key is recovered from the signature; otherwise the signature is
+// verified against the key returned by resolver. In both cases the key must map to the
+// address in the X-Requester-Address header.
+//
+// The request body is read and replaced, so it can still be consumed afterwards.
+func VerifyRequest(req *http.Request, resolver PubKeyResolver, transferAddress string) error {
+	sigHeader := req.Header.Get("Authorization")
+	if sigHeader == "" {
+		return ErrMissingSignature
+	}
+	tsHeader := req.Header.Get("X-Timestamp")
+	if tsHeader == "" {
+		return ErrMissingTimestamp
+	}
+	requester := req.Header.Get("X-Requester-Address")
+	if requester == "" {
+		return ErrMissingRequesterAddress
+	}
+
+	timestamp, err := strconv.ParseInt(tsHeader, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTimestamp, err)
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(sigHeader)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrMalformedSignature, err)
+	}
+	if len(sig) != 64 {
+		return fmt.Errorf("%w: expected 64 bytes, got %d", ErrMalformedSignature, len(sig))
+	}
+
+	var payload []byte
+	if req.Body != nil {
+		payload, err = io.ReadAll(req.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read request body: %w", err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(payload))
+	}
+
+	hash := sha256.Sum256(getSignatureBytes(SignatureComponents{
+		Payload:         string(payload),
+		Timestamp:       timestamp,
+		TransferAddress: transferAddress,
+	}))
+
+	prefix, _, err := DecodeAddress(requester)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrAddressMismatch, err)
+	}
+
+	if resolver != nil {
+		pub, err := resolver(req.Context(), requester)
+		if err != nil {
+			return fmt.Errorf("failed to resolve public key for %s: %w", requester, err)
+		}
+		if !crypto.VerifySignature(pub, hash[:], sig) {
+			return ErrInvalidSignature
+		}
+		addr, err := pubKeyToAddress(pub, prefix)
+		if err != nil {
+			return err
+		}
+		if addr != requester {
+			return fmt.Errorf("%w: key belongs to %s, header is %s", ErrAddressMismatch, addr, requester)
+		}
+		return nil
+	}
+
+	// Without a resolver, recover the key from the signature
+	return recoverSigner(hash[:], sig, requester, prefix)
+}
+
+// recoverSigner checks that the 64-byte signature over hash was made by the key
+// behind address, trying both recovery ids.
+func recoverSigner(hash, sig []byte, address, prefix string) error {
+	for v := byte(0); v < 2; v++ {
+		pub, err := crypto.SigToPub(hash, append(sig[:64:64], v))
+		if err != nil {
+			continue
+		}
+		compressed := crypto.CompressPubkey(pub)
+		if !crypto.VerifySignature(compressed, hash, sig) {
+			return ErrInvalidSignature
+		}
+		addr, err := pubKeyToAddress(compressed, prefix)
+		if err != nil {
+			return err
+		}
+		if addr == address {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrAddressMismatch, address)
+}
@@ -0,0 +1,140 @@
+package gonkaopenai
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testPrivateKey      = "10af8dc1f63fb90cfa39943a5afbf262cd84f24919e7d05653e3b03313e685ce"
+	testTransferAddress = "gonka1l3e9pgs3mmwuwrh95fecme0s0qtn2880el49wm"
+)
+
+// newSignedRequest signs a request through signingRoundTripper and captures it before it leaves.
+func newSignedRequest(t *testing.T, body string) *http.Request {
+	t.Helper()
+	var captured *http.Request
+	rt := signingRoundTripper{
+		rt: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			captured = req
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+		}),
+		keys:      testKeyRing(t, testPrivateKey),
+		endpoints: []Endpoint{{URL: "http://participant.test/v1", Address: testTransferAddress}},
+	}
+	req, err := http.NewRequest(http.MethodPost, "http://participant.test/v1/chat/completions", strings.NewReader(body))
+	require.NoError(t, err)
+	_, err = rt.RoundTrip(req)
+	require.NoError(t, err)
+	return captured
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// testKeyRing returns a ring signing with key. Keys that do not parse are put
+// in the ring as they are, to exercise signing failures.
+func testKeyRing(t *testing.T, key string) *KeyRing {
+	t.Helper()
+	ring, err := newKeyRing(Testnet, KeyPolicyRoundRobin, false, []SigningKey{{PrivateKey: testPrivateKey}})
+	require.NoError(t, err)
+	ring.keys[0].privateKey = key
+	return ring
+}
+
+func mustAddress(t *testing.T, key string) string {
+	t.Helper()
+	addr, err := GonkaAddress(key)
+	require.NoError(t, err)
+	return addr
+}
+
+func TestVerifyRequest_Recover(t *testing.T) {
+	req := newSignedRequest(t, `{"model":"m"}`)
+	require.NoError(t, VerifyRequest(req, nil, testTransferAddress))
+
+	// The body must still be readable after verification.
+	body, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	assert.Equal(t, `{"model":"m"}`, string(body))
+}
+
+func TestVerifyRequest_Resolver(t *testing.T) {
+	keyBytes, _ := hex.DecodeString(testPrivateKey)
+	priv, err := crypto.ToECDSA(keyBytes)
+	require.NoError(t, err)
+	pub := crypto.CompressPubkey(&priv.PublicKey)
+
+	resolver := func(ctx context.Context, address string) ([]byte, error) { return pub, nil }
+	req := newSignedRequest(t, `{"model":"m"}`)
+	assert.NoError(t, VerifyRequest(req, resolver, testTransferAddress))
+
+	failing := func(ctx context.Context, address string) ([]byte, error) { return nil, errors.New("unknown") }
+	req = newSignedRequest(t, `{"model":"m"}`)
+	assert.Error(t, VerifyRequest(req, failing, testTransferAddress))
+}
+
+func TestVerifyRequest_Rejects(t *testing.T) {
+	req := newSignedRequest(t, `{"model":"m"}`)
+	req.Body = io.NopCloser(strings.NewReader(`{"model":"other"}`))
+	assert.ErrorIs(t, VerifyRequest(req, nil, testTransferAddress), ErrAddressMismatch)
+
+	req = newSignedRequest(t, `{"model":"m"}`)
+	assert.ErrorIs(t, VerifyRequest(req, nil, "gonka1someoneelse"), ErrAddressMismatch)
+
+	// The checksum is verified before any key is recovered
+	req = newSignedRequest(t, `{"model":"m"}`)
+	addr := req.Header.Get("X-Requester-Address")
+	req.Header.Set("X-Requester-Address", addr[:len(addr)-1]+"q")
+	assert.ErrorIs(t, VerifyRequest(req, nil, testTransferAddress), ErrInvalidAddress)
+
+	req = newSignedRequest(t, `{"model":"m"}`)
+	req.Header.Set("X-Timestamp", "abc")
+	assert.ErrorIs(t, VerifyRequest(req, nil, testTransferAddress), ErrInvalidTimestamp)
+
+	req = newSignedRequest(t, `{"model":"m"}`)
+	req.Header.Del("Authorization")
+	assert.ErrorIs(t, VerifyRequest(req, nil, testTransferAddress), ErrMissingSignature)
+
+	req = newSignedRequest(t, `{"model":"m"}`)
+	req.Header.Set("Authorization", "AAAA")
+	assert.ErrorIs(t, VerifyRequest(req, nil, testTransferAddress), ErrMalformedSignature)
+}
+
+func TestVerifyRequest_PrefixWithSeparator(t *testing.T) {
+	// The bech32 separator is the last "1", so prefixes may contain one
+	addr, err := Network{Bech32Prefix: "test1net"}.Address(testPrivateKey)
+	require.NoError(t, err)
+	req := newSignedRequest(t, `{"model":"m"}`)
+	req.Header.Set("X-Requester-Address", addr)
+	assert.NoError(t, VerifyRequest(req, nil, testTransferAddress))
+}
+
+func TestVerifyRequest_HTTPServer(t *testing.T) {
+	var verifyErr error
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		verifyErr = VerifyRequest(r, nil, testTransferAddress)
+	}))
+	defer srv.Close()
+
+	client, err := GonkaHTTPClient(HTTPClientOptions{
+		PrivateKey: testPrivateKey,
+		Endpoints:  []Endpoint{{URL: srv.URL + "/v1", Address: testTransferAddress}},
+	})
+	require.NoError(t, err)
+	resp, err := client.Post(srv.URL+"/v1/chat/completions", "application/json", strings.NewReader(`{}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.NoError(t, verifyErr)
+}